@@ -0,0 +1,61 @@
+package assets
+
+//go:generate go run generate.go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// file is a Docker asset embedded in the binary.
+type file struct {
+	mode    os.FileMode
+	content string
+}
+
+// Names returns the names of the embedded Docker assets: the Dockerfiles and the start script
+// of the Swarm images.
+func Names() []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Write writes the embedded Docker assets into dir.
+func Write(dir string) error {
+	for name, f := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(f.content), f.mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Dir returns the directory to build the Swarm images from and a function removing it once the
+// build is done. A custom asset directory is used as it is. Otherwise the embedded assets are
+// written into a new temporary directory.
+func Dir(custom string) (string, func(), error) {
+	if custom != "" {
+		return custom, func() {}, nil
+	}
+
+	dir, err := ioutil.TempDir("", "swarmer-assets")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	if err := Write(dir); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return dir, cleanup, nil
+}
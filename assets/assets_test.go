@@ -0,0 +1,108 @@
+package assets
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilesUpToDate(t *testing.T) {
+	entries, err := ioutil.ReadDir(filepath.Join("..", "docker"))
+	if err != nil {
+		t.Fatalf("Error reading docker directory: %s", err.Error())
+	}
+
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join("..", "docker", entry.Name()))
+		if err != nil {
+			t.Fatalf("Error reading %s: %s", entry.Name(), err.Error())
+		}
+		if f, ok := files[entry.Name()]; !ok || f.content != string(content) {
+			t.Errorf("The embedded %s is out of date, run go generate ./assets", entry.Name())
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir, cleanup, err := Dir("")
+	if err != nil {
+		t.Fatalf("Error writing assets: %s", err.Error())
+	}
+
+	for _, name := range []string{"Dockerfile", "Dockerfile.SrcDir", "Dockerfile.Binary", "start.sh"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Asset directory should contain %s", name)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, "start.sh")); err != nil || info.Mode()&0100 == 0 {
+		t.Error("start.sh should be executable")
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Asset directory %s should have been removed", dir)
+	}
+
+	custom, cleanup, err := Dir("../docker")
+	if err != nil || custom != "../docker" {
+		t.Errorf("A custom asset directory should be used as it is, got %s, %v", custom, err)
+	}
+	cleanup()
+	if _, err := os.Stat("../docker"); err != nil {
+		t.Error("A custom asset directory shouldn't be removed")
+	}
+}
+
+func TestStartScriptGeth(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is needed to run start.sh")
+	}
+
+	for _, geth := range []string{"true", "false"} {
+		dir, err := ioutil.TempDir("", "swarmer-start")
+		if err != nil {
+			t.Fatalf("Error creating temporary directory: %s", err.Error())
+		}
+		defer os.RemoveAll(dir)
+
+		// the script runs against stand-ins for geth, swarm and jq in place of /app, and waits
+		// for geth instead of running forever
+		script := strings.Replace(files["start.sh"].content, "/app", dir, -1)
+		script = strings.Replace(script, "tail -f /dev/null", "wait", -1)
+		stubs := map[string]string{
+			"start.sh":  script,
+			"bin/geth":  "#!/bin/sh\nif [ \"$3\" = account ]; then mkdir -p " + dir + "/keystore && touch " + dir + "/keystore/key; else touch " + dir + "/geth_started; fi\n",
+			"bin/swarm": "#!/bin/sh\n",
+			"bin/jq":    "#!/bin/sh\necho 0x0\n",
+		}
+		if err := os.Mkdir(filepath.Join(dir, "bin"), 0755); err != nil {
+			t.Fatalf("Error creating bin directory: %s", err.Error())
+		}
+		for name, content := range stubs {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+				t.Fatalf("Error writing %s: %s", name, err.Error())
+			}
+		}
+
+		cmd := exec.Command("bash", filepath.Join(dir, "start.sh"))
+		cmd.Env = []string{"GETH=" + geth, "PATH=" + filepath.Join(dir, "bin") + ":" + os.Getenv("PATH")}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Error running start.sh with GETH=%s: %s\n%s", geth, err.Error(), output)
+		}
+
+		_, err = os.Stat(filepath.Join(dir, "geth_started"))
+		if started := err == nil; started != (geth == "true") {
+			t.Errorf("With GETH=%s start.sh should start geth: %t, started it: %t", geth, geth == "true", started)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "keystore", "key")); err != nil {
+			t.Errorf("With GETH=%s start.sh should create the account of the node", geth)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build ignore
+// +build ignore
+
+// This program writes files.go with the contents of the docker directory. It is run by
+// go generate in this directory.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+const header = `// Code generated by go run generate.go; DO NOT EDIT.
+
+package assets
+
+var files = map[string]file{
+`
+
+func main() {
+	entries, err := ioutil.ReadDir(filepath.Join("..", "docker"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join("..", "docker", entry.Name()))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// git only keeps the executable bit
+		mode := 0644
+		if entry.Mode()&0100 != 0 {
+			mode = 0755
+		}
+		fmt.Fprintf(&buf, "\t%q: {mode: %#o, content: %q},\n", entry.Name(), mode, content)
+	}
+	buf.WriteString("}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile("files.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
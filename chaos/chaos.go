@@ -0,0 +1,203 @@
+package chaos
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// Fault actions that can be applied to a node.
+const (
+	Kill       = "kill"
+	Pause      = "pause"
+	Unpause    = "unpause"
+	Restart    = "restart"
+	Disconnect = "disconnect"
+	Reconnect  = "reconnect"
+)
+
+// Actions lists every fault action.
+var Actions = []string{Kill, Pause, Unpause, Restart, Disconnect, Reconnect}
+
+// reverts maps the faults that can be undone to the action undoing them.
+var reverts = map[string]string{
+	Kill:       Restart,
+	Pause:      Unpause,
+	Disconnect: Reconnect,
+}
+
+// Node is a node container of a cluster, running or not.
+type Node struct {
+	Index int
+	ID    string
+	Name  string
+}
+
+// IChaos is the interface for applying faults to the nodes of a cluster.
+type IChaos interface {
+	Nodes(ctx context.Context, cluster string) ([]Node, error)
+	Apply(ctx context.Context, action string, node Node, network string) error
+}
+
+// Chaos is the struct for this implementation of IChaos.
+type Chaos struct {
+	dockerClient *client.Client
+}
+
+// GetChaos returns a pointer to a new instance of this implementation of IChaos.
+func GetChaos(d *client.Client) *Chaos {
+	var c = Chaos{
+		dockerClient: d,
+	}
+
+	return &c
+}
+
+// Nodes returns every node container of the cluster, including stopped and paused ones,
+// ordered by node index.
+func (c *Chaos) Nodes(ctx context.Context, cluster string) ([]Node, error) {
+	args := filters.NewArgs()
+	for k, v := range orchestrator.Labels(cluster) {
+		args.Add("label", k+"="+v)
+	}
+
+	containers, err := c.dockerClient.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []Node
+	for _, container := range containers {
+		index, ok := orchestrator.NodeIndex(container.Labels)
+		if !ok {
+			continue
+		}
+
+		var name string
+		if len(container.Names) > 0 {
+			name = strings.TrimPrefix(container.Names[0], "/")
+		}
+		nodes = append(nodes, Node{Index: index, ID: container.ID, Name: name})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Index < nodes[j].Index })
+
+	return nodes, nil
+}
+
+// Apply applies a single fault action to the node. network is the cluster network the node is
+// disconnected from or reconnected to.
+func (c *Chaos) Apply(ctx context.Context, action string, node Node, network string) error {
+	switch action {
+	case Kill:
+		return c.dockerClient.ContainerKill(ctx, node.ID, "SIGKILL")
+	case Pause:
+		return c.dockerClient.ContainerPause(ctx, node.ID)
+	case Unpause:
+		return c.dockerClient.ContainerUnpause(ctx, node.ID)
+	case Restart:
+		return c.dockerClient.ContainerRestart(ctx, node.ID, nil)
+	case Disconnect:
+		return c.dockerClient.NetworkDisconnect(ctx, network, node.ID, true)
+	case Reconnect:
+		return c.dockerClient.NetworkConnect(ctx, network, node.ID, nil)
+	}
+
+	return ValidateAction(action)
+}
+
+// ValidateAction returns an error if action is not one of Actions.
+func ValidateAction(action string) error {
+	for _, a := range Actions {
+		if a == action {
+			return nil
+		}
+	}
+
+	return errors.Errorf("unknown chaos action %q, expected one of %s", action, strings.Join(Actions, ", "))
+}
+
+// Select returns the nodes with the given indexes, followed by random distinct nodes from the
+// rest until random more have been chosen.
+func Select(nodes []Node, indexes []int, random int, r *rand.Rand) ([]Node, error) {
+	if len(indexes) == 0 && random <= 0 {
+		return nil, errors.Errorf("no nodes selected, give node indexes or a number of random nodes")
+	}
+
+	chosen := map[int]bool{}
+	var selected []Node
+
+	for _, index := range indexes {
+		found := false
+		for _, node := range nodes {
+			if node.Index == index {
+				found = true
+				if !chosen[index] {
+					chosen[index] = true
+					selected = append(selected, node)
+				}
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("node %d is not part of the cluster", index)
+		}
+	}
+
+	var rest []Node
+	for _, node := range nodes {
+		if !chosen[node.Index] {
+			rest = append(rest, node)
+		}
+	}
+	if random > len(rest) {
+		return nil, errors.Errorf("can't pick %d random nodes from the %d left", random, len(rest))
+	}
+
+	for _, i := range r.Perm(len(rest))[:random] {
+		selected = append(selected, rest[i])
+	}
+
+	return selected, nil
+}
+
+// Inject applies action to every node in turn, writing a ChaosEvent for each to events as a line
+// of JSON. It carries on past nodes the action fails on and returns how many there were.
+func Inject(ctx context.Context, c IChaos, cluster string, action string, nodes []Node, seed int64, events io.Writer) (int, error) {
+	encoder := json.NewEncoder(events)
+	network := orchestrator.NetworkName(cluster)
+
+	failed := 0
+	for _, node := range nodes {
+		event := models.ChaosEvent{
+			Cluster:   cluster,
+			Action:    action,
+			Node:      node.Index,
+			Container: node.Name,
+			Seed:      seed,
+		}
+
+		if err := c.Apply(ctx, action, node, network); err != nil {
+			event.Error = err.Error()
+			failed++
+		}
+		event.Time = time.Now().UTC().Format(time.RFC3339Nano)
+
+		if err := encoder.Encode(event); err != nil {
+			return failed, err
+		}
+	}
+
+	return failed, nil
+}
@@ -0,0 +1,106 @@
+package chaos
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// fakeChaos records the actions applied to a fixed set of nodes instead of calling Docker.
+type fakeChaos struct {
+	mu      sync.Mutex
+	nodes   []Node
+	fail    map[int]bool
+	applied []string
+}
+
+func (f *fakeChaos) Nodes(ctx context.Context, cluster string) ([]Node, error) {
+	return f.nodes, nil
+}
+
+func (f *fakeChaos) Apply(ctx context.Context, action string, node Node, network string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.applied = append(f.applied, action+" "+node.Name)
+	if f.fail[node.Index] {
+		return errors.Errorf("node %d is gone", node.Index)
+	}
+
+	return nil
+}
+
+func testNodes() []Node {
+	return []Node{{0, "a", "ci_swarm_0"}, {1, "b", "ci_swarm_1"}, {2, "c", "ci_swarm_2"}, {3, "d", "ci_swarm_3"}}
+}
+
+func TestSelect(t *testing.T) {
+	nodes := testNodes()
+
+	selected, err := Select(nodes, []int{2, 2, 0}, 0, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(selected, []Node{nodes[2], nodes[0]}) {
+		t.Errorf("Expected nodes 2 and 0, got %v", selected)
+	}
+
+	first, _ := Select(nodes, []int{1}, 2, rand.New(rand.NewSource(7)))
+	second, _ := Select(nodes, []int{1}, 2, rand.New(rand.NewSource(7)))
+	if len(first) != 3 || first[0] != nodes[1] || !reflect.DeepEqual(first, second) {
+		t.Errorf("Random selections with the same seed should match and skip chosen nodes, got %v and %v", first, second)
+	}
+	if first[1] == nodes[1] || first[2] == nodes[1] || first[1] == first[2] {
+		t.Errorf("Random nodes should be distinct, got %v", first)
+	}
+
+	for _, test := range []struct {
+		indexes []int
+		random  int
+	}{{nil, 0}, {[]int{4}, 0}, {[]int{0}, 4}} {
+		if _, err := Select(nodes, test.indexes, test.random, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("Selecting %v and %d random nodes should have thrown an error...", test.indexes, test.random)
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	fake := &fakeChaos{nodes: testNodes(), fail: map[int]bool{3: true}}
+	var events bytes.Buffer
+
+	failed, err := Inject(context.Background(), fake, "ci", Kill, []Node{fake.nodes[1], fake.nodes[3]}, 42, &events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 1 {
+		t.Errorf("Expected the action to fail on 1 node, got %d", failed)
+	}
+
+	var logged []models.ChaosEvent
+	scanner := bufio.NewScanner(&events)
+	for scanner.Scan() {
+		var event models.ChaosEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Events should be lines of JSON, got %q", scanner.Text())
+		}
+		logged = append(logged, event)
+	}
+
+	if len(logged) != 2 {
+		t.Fatalf("Expected an event per node, got %v", logged)
+	}
+	if logged[0].Action != Kill || logged[0].Node != 1 || logged[0].Container != "ci_swarm_1" || logged[0].Seed != 42 || logged[0].Error != "" || logged[0].Time == "" {
+		t.Errorf("Unexpected event %+v", logged[0])
+	}
+	if logged[1].Node != 3 || logged[1].Error != "node 3 is gone" {
+		t.Errorf("The failure should be recorded in the event, got %+v", logged[1])
+	}
+}
@@ -0,0 +1,150 @@
+package chaos
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+	"gopkg.in/yaml.v2"
+)
+
+// Plan is a schedule of faults to apply to a cluster over time.
+type Plan struct {
+	// Seed makes the random node selections of the plan reproducible. 0 picks a seed from the
+	// current time, which is recorded in the events.
+	Seed  int64  `yaml:"seed"`
+	Steps []Step `yaml:"steps"`
+}
+
+// Step is a single fault of a plan.
+type Step struct {
+	// At is how long after the plan starts the fault is applied.
+	At     time.Duration `yaml:"at"`
+	Action string        `yaml:"action"`
+	// Nodes are the indexes of the nodes to apply the fault to.
+	Nodes []int `yaml:"nodes"`
+	// Random is the number of nodes, besides Nodes, to pick at random when the step runs.
+	Random int `yaml:"random"`
+	// For is how long until the fault is undone, 0 meaning never. Only kill, pause and
+	// disconnect can be undone.
+	For time.Duration `yaml:"for"`
+}
+
+// scheduled is a step, or the undoing of one, waiting to be run.
+type scheduled struct {
+	at     time.Duration
+	action string
+	step   Step
+	// nodes are the nodes a revert applies to, chosen when the step it undoes ran
+	nodes []Node
+}
+
+// ParsePlan reads and checks the YAML fault plan at path.
+func ParsePlan(path string) (Plan, error) {
+	var plan Plan
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return plan, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, &plan); err != nil {
+		return plan, errors.Errorf("Error parsing chaos plan %s: %s", path, err.Error())
+	}
+
+	if len(plan.Steps) == 0 {
+		return plan, errors.Errorf("Chaos plan %s has no steps", path)
+	}
+
+	for i, step := range plan.Steps {
+		if err := ValidateAction(step.Action); err != nil {
+			return plan, errors.Errorf("Step %d of chaos plan %s: %s", i, path, err.Error())
+		}
+		if step.At < 0 || step.For < 0 {
+			return plan, errors.Errorf("Step %d of chaos plan %s: at and for must not be negative", i, path)
+		}
+		if len(step.Nodes) == 0 && step.Random <= 0 {
+			return plan, errors.Errorf("Step %d of chaos plan %s: give nodes or a number of random nodes", i, path)
+		}
+		if _, ok := reverts[step.Action]; step.For > 0 && !ok {
+			return plan, errors.Errorf("Step %d of chaos plan %s: %s can't be undone, remove for", i, path, step.Action)
+		}
+	}
+
+	return plan, nil
+}
+
+// RunPlan applies the steps of the plan to the cluster as their time comes, undoing faults
+// with a duration once it is up, and writes every action as a ChaosEvent line of JSON to
+// events. Nodes are selected when their step runs. Failed actions are logged and the plan
+// carries on; an error is returned at the end if any failed, or straight away if ctx is done.
+func RunPlan(ctx context.Context, c IChaos, cluster string, plan Plan, events io.Writer) error {
+	seed := plan.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	var queue []scheduled
+	for _, step := range plan.Steps {
+		queue = append(queue, scheduled{at: step.At, action: step.Action, step: step})
+	}
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].at < queue[j].at })
+
+	start := time.Now()
+	failed := 0
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(next.at - time.Since(start)):
+		}
+
+		nodes := next.nodes
+		if nodes == nil {
+			all, err := c.Nodes(ctx, cluster)
+			if err != nil {
+				return errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+			}
+
+			nodes, err = Select(all, next.step.Nodes, next.step.Random, r)
+			if err != nil {
+				return errors.Errorf("Error selecting nodes to %s: %s", next.action, err.Error())
+			}
+		}
+
+		eventSeed := int64(0)
+		if next.step.Random > 0 {
+			eventSeed = seed
+		}
+
+		n, err := Inject(ctx, c, cluster, next.action, nodes, eventSeed, events)
+		if err != nil {
+			return err
+		}
+		failed += n
+
+		if next.nodes == nil && next.step.For > 0 {
+			queue = append(queue, scheduled{
+				at:     next.at + next.step.For,
+				action: reverts[next.action],
+				step:   next.step,
+				nodes:  nodes,
+			})
+			sort.SliceStable(queue, func(i, j int) bool { return queue[i].at < queue[j].at })
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d of the chaos plan's actions failed, see the events for details", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package chaos
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestParsePlan(t *testing.T) {
+	plan, err := ParsePlan("testdata/plan.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Seed != 42 || len(plan.Steps) != 3 {
+		t.Fatalf("Unexpected plan %+v", plan)
+	}
+	if step := plan.Steps[0]; step.At != 20*time.Millisecond || step.Action != Pause || step.For != 20*time.Millisecond || !reflect.DeepEqual(step.Nodes, []int{1}) {
+		t.Errorf("Unexpected first step %+v", step)
+	}
+
+	if _, err := ParsePlan("testdata/invalid.yml"); err == nil || !strings.Contains(err.Error(), "can't be undone") {
+		t.Errorf("A restart with a duration should have thrown an error, got %v", err)
+	}
+	if _, err := ParsePlan("non existent file"); err == nil {
+		t.Error("Trying to parse a missing plan should have thrown an error...")
+	}
+}
+
+func TestRunPlan(t *testing.T) {
+	plan, err := ParsePlan("testdata/plan.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeChaos{nodes: testNodes()}
+	var events bytes.Buffer
+
+	if err := RunPlan(context.Background(), fake, "ci", plan, &events); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.applied) != 4 {
+		t.Fatalf("Expected 4 actions, got %v", fake.applied)
+	}
+	if !strings.HasPrefix(fake.applied[0], Disconnect+" ") {
+		t.Errorf("Steps should run in the order of their time, got %v", fake.applied)
+	}
+
+	// the pause is undone at 40ms, after the restart at 30ms
+	expected := []string{Pause + " ci_swarm_1", Restart + " ci_swarm_0", Unpause + " ci_swarm_1"}
+	if !reflect.DeepEqual(fake.applied[1:], expected) {
+		t.Errorf("Expected %v after the disconnect, got %v", expected, fake.applied[1:])
+	}
+	if lines := strings.Count(events.String(), "\n"); lines != 4 {
+		t.Errorf("Expected an event for every action, got %d", lines)
+	}
+}
+
+func TestRunPlan_Cancel(t *testing.T) {
+	plan := Plan{Steps: []Step{{At: time.Hour, Action: Kill, Nodes: []int{0}}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := RunPlan(ctx, &fakeChaos{nodes: testNodes()}, "ci", plan, &bytes.Buffer{}); err != context.Canceled {
+		t.Errorf("Expected the plan to stop when cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+package cluster
+
+import (
+	"os"
+
+	"github.com/MainframeHQ/swarmer/admin"
+	"github.com/MainframeHQ/swarmer/images"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/MainframeHQ/swarmer/partition"
+	"github.com/MainframeHQ/swarmer/readiness"
+	"github.com/MainframeHQ/swarmer/state"
+	"github.com/MainframeHQ/swarmer/topology"
+	"github.com/MainframeHQ/swarmer/validation"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// DockerAPIVersion is the Docker API version swarmer talks to the Docker host with.
+const DockerAPIVersion = "1.38"
+
+// ICluster is the interface for driving a Swarm cluster.
+type ICluster interface {
+	Start(ctx context.Context) error
+	Nodes() []models.NodeInfo
+	Running(ctx context.Context) ([]models.NodeInfo, bool, error)
+	Status(ctx context.Context) ([]models.NodeInfo, error)
+	Scale(ctx context.Context, count int) error
+	Shape(ctx context.Context, rules []models.Netem, indexes []int) ([]models.NetemStatus, error)
+	Partition(ctx context.Context, groups []partition.Group) ([]models.PartitionStatus, error)
+	Stop(ctx context.Context) error
+	Destroy(ctx context.Context) error
+	Teardown(ctx context.Context, keepVolumes bool, keepImages bool) (models.TeardownInfo, error)
+}
+
+// Cluster is the struct for this implementation of ICluster.
+type Cluster struct {
+	config       models.Config
+	dockerClient *client.Client
+	orchestrator orchestrator.IOrchestrator
+	readiness    readiness.IChecker
+	adminClient  admin.IClient
+	store        state.IStore
+	images       images.IImages
+	sources      map[string]string
+	nodes        []models.NodeInfo
+}
+
+// New returns a cluster for the given config, talking to the Docker host given by the DOCKER_*
+// environment variables. Values the config leaves empty get the same defaults as the CLI flags.
+func New(cfg models.Config) (*Cluster, error) {
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion(DockerAPIVersion))
+	if err != nil {
+		return nil, errors.Errorf("Must have Docker compatible with API %s: %s", DockerAPIVersion, err.Error())
+	}
+
+	adminClient := admin.GetClient()
+
+	return GetCluster(
+		cfg,
+		dockerClient,
+		orchestrator.GetOrchestrator(dockerClient),
+		readiness.GetChecker(adminClient),
+		adminClient,
+		state.GetStore(state.DefaultDir),
+		images.GetImages(dockerClient),
+		nil,
+	), nil
+}
+
+// GetCluster returns a pointer to a new instance of this implementation of ICluster. Values the
+// config leaves empty are set by WithDefaults. sources maps config keys to where their values came
+// from, to point at them in validation errors.
+func GetCluster(
+	c models.Config,
+	d *client.Client,
+	o orchestrator.IOrchestrator,
+	r readiness.IChecker,
+	a admin.IClient,
+	st state.IStore,
+	i images.IImages,
+	sources map[string]string,
+) *Cluster {
+	var s = Cluster{
+		config:       WithDefaults(c),
+		dockerClient: d,
+		orchestrator: o,
+		readiness:    r,
+		adminClient:  a,
+		store:        st,
+		images:       i,
+		sources:      sources,
+	}
+
+	return &s
+}
+
+// WithDefaults returns the config with the values it leaves empty set to the defaults of the
+// CLI flags.
+func WithDefaults(cfg models.Config) models.Config {
+	if cfg.Cluster == "" {
+		cfg.Cluster = orchestrator.DefaultCluster
+	}
+	if cfg.Nodes.Count == 0 {
+		cfg.Nodes.Count = 1
+	}
+	if cfg.DockerLog == "" {
+		cfg.DockerLog = "docker_log"
+	}
+	if cfg.SwarmLog == "" {
+		cfg.SwarmLog = "swarm_log"
+	}
+	if cfg.Output == "" {
+		cfg.Output = output.JSON
+	}
+	if cfg.ReadyTimeout == 0 {
+		cfg.ReadyTimeout = readiness.DefaultTimeout
+	}
+	if cfg.ReadyBackoff == 0 {
+		cfg.ReadyBackoff = readiness.DefaultBackoff
+	}
+	if cfg.Topology.Type == "" {
+		cfg.Topology.Type = "ring"
+	}
+	if cfg.Topology.Degree == 0 {
+		cfg.Topology.Degree = 2
+	}
+
+	return cfg
+}
+
+// Start builds or pulls the Swarm images and starts the configured number of nodes, replacing any
+// nodes of the cluster that are already there. It returns once every node is ready and peered, and
+// the nodes are shaped by the netem rules of the config. If ctx is cancelled before then, the
+// containers, volumes and network of the cluster are removed and an *InterruptedError returned.
+// Running nodes started from the same image with the same settings are kept as they are, unless
+// the config asks to recreate them.
+func (s *Cluster) Start(ctx context.Context) (err error) {
+
+	err = validation.Validate(s.config, s.sources)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	created := false
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			if created {
+				err = s.rollback(err)
+			} else {
+				err = &InterruptedError{Err: err, Removed: models.TeardownInfo{Cluster: s.config.Cluster}}
+			}
+		}
+	}()
+
+	networkName := orchestrator.NetworkName(s.config.Cluster)
+	labels := orchestrator.Labels(s.config.Cluster)
+
+	buildLog, err := os.Create(s.config.DockerLog)
+	if err != nil {
+		return errors.Errorf("Error creating docker log file on host: %s", err.Error())
+	}
+	defer buildLog.Close()
+
+	nodeImages, err := s.nodeImages(ctx, buildLog)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	if !s.config.Recreate {
+		reused, err := s.reuse(ctx, nodeImages)
+		if err != nil || reused {
+			return err
+		}
+	}
+
+	created = true
+
+	_, _, err = s.orchestrator.RemoveContainers(ctx, labels, true)
+	if err != nil {
+		return errors.Errorf("Error removing existing Swarm containers: %s", err.Error())
+	}
+
+	_, err = s.orchestrator.CreateNetwork(ctx, networkName, labels)
+	if err != nil {
+		return errors.Errorf("Error creating Docker network %s: %s", networkName, err.Error())
+	}
+
+	var containerIDs []string
+	for i := 0; i < s.config.Nodes.Count; i++ {
+		spec, err := nodeSpec(s.config, i, nodeImages[i], networkName)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		id, err := s.orchestrator.RunNode(ctx, spec)
+		if err != nil {
+			return errors.Errorf("Error starting Swarm node: %s", err.Error())
+		}
+
+		containerIDs = append(containerIDs, id)
+	}
+
+	var containers []types.ContainerJSON
+	var targets []readiness.Target
+	for _, containerID := range containerIDs {
+		container, err := s.dockerClient.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return errors.Errorf("Error inspecting container %s: %s", containerID, err.Error())
+		}
+
+		containers = append(containers, container)
+		target, err := readinessTarget(container)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+		targets = append(targets, target)
+	}
+
+	err = s.readiness.WaitAll(ctx, targets, s.config.ReadyTimeout, s.config.ReadyBackoff)
+	if err != nil {
+		return errors.Errorf("Error waiting for Swarm nodes to become ready: %s", err.Error())
+	}
+
+	logIDs := map[int]string{}
+	for i, containerID := range containerIDs {
+		logIDs[i] = containerID
+	}
+
+	err = s.writeLogs(ctx, logIDs)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	nodes := map[int]models.NodeInfo{}
+	var nodeResults []models.NodeInfo
+
+	// get admin_nodeInfo data
+	for i, container := range containers {
+		info, err := nodeInfo(ctx, s.adminClient, container, networkName)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		nodes[i] = info
+		nodeResults = append(nodeResults, info)
+	}
+
+	// peering
+	edges, err := topology.Edges(s.config.Topology, len(nodeResults))
+	if err != nil {
+		return errors.Errorf("Error building peering topology: %s", err.Error())
+	}
+
+	err = peer(ctx, s.adminClient, "admin_addPeer", nodes, edges)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	s.nodes = nodeResults
+
+	if len(s.config.Netem) > 0 {
+		_, err = s.Shape(ctx, s.config.Netem, nil)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	return s.save(edges)
+}
+
+// rollback removes what an interrupted start created, keeping the image as a build cache.
+func (s *Cluster) rollback(cause error) error {
+	// ctx is done, so the removal gets a context of its own
+	removed, err := s.Teardown(context.Background(), false, true)
+
+	return &InterruptedError{Err: cause, Removed: removed, RollbackErr: err}
+}
+
+// Nodes returns the details of the nodes started by Start or Scale, ordered by node index.
+func (s *Cluster) Nodes() []models.NodeInfo {
+	return s.nodes
+}
+
+// Stop stops the nodes of the cluster recorded in its state file, keeping their containers. The
+// running nodes of the cluster are listed only if there is no state file.
+func (s *Cluster) Stop(ctx context.Context) error {
+	st, ok, err := s.store.Load(s.config.Cluster)
+	if err != nil {
+		return errors.Errorf("Error reading state of cluster %s: %s", s.config.Cluster, err.Error())
+	}
+
+	var ids []string
+	if ok {
+		for _, node := range st.Nodes {
+			ids = append(ids, node.ContainerID)
+		}
+	} else {
+		running, err := s.orchestrator.ListNodes(ctx, orchestrator.Labels(s.config.Cluster))
+		if err != nil {
+			return errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+		}
+		for _, container := range running {
+			ids = append(ids, container.ID)
+		}
+	}
+
+	for _, id := range ids {
+		// a recorded container may have been removed since
+		err := s.dockerClient.ContainerStop(ctx, id, nil)
+		if err != nil && !client.IsErrNotFound(err) {
+			return errors.Errorf("Error stopping container %s: %s", id, err.Error())
+		}
+	}
+	s.nodes = nil
+
+	return s.store.Remove(s.config.Cluster)
+}
+
+// Destroy removes the containers, volumes and network of the cluster, along with the Swarm images
+// it was started from that no other cluster uses.
+func (s *Cluster) Destroy(ctx context.Context) error {
+	_, err := s.Teardown(ctx, false, false)
+
+	return err
+}
+
+// Teardown removes the containers, network and optionally the volumes of the cluster, along with
+// the Swarm images built for it that nothing else uses unless keepImages is set, and returns what
+// was removed.
+func (s *Cluster) Teardown(ctx context.Context, keepVolumes bool, keepImages bool) (models.TeardownInfo, error) {
+	labels := orchestrator.Labels(s.config.Cluster)
+	result := models.TeardownInfo{Cluster: s.config.Cluster}
+
+	imageIDs, err := s.orchestrator.ContainerImages(ctx, labels)
+	if err != nil {
+		return result, errors.Errorf("Error listing Swarm containers: %s", err.Error())
+	}
+
+	result.Containers, result.Volumes, err = s.orchestrator.RemoveContainers(ctx, labels, !keepVolumes)
+	if err != nil {
+		return result, errors.Errorf("Error removing Swarm containers: %s", err.Error())
+	}
+	s.nodes = nil
+
+	err = s.store.Remove(s.config.Cluster)
+	if err != nil {
+		return result, errors.Errorf("Error removing state of cluster %s: %s", s.config.Cluster, err.Error())
+	}
+
+	result.Networks, err = s.orchestrator.RemoveNetworks(ctx, labels)
+	if err != nil {
+		return result, errors.Errorf("Error removing Swarm networks: %s", err.Error())
+	}
+
+	if !keepImages {
+		result.Images, err = s.images.Remove(ctx, imageIDs)
+		if err != nil {
+			return result, errors.Errorf("Error removing Swarm images: %s", err.Error())
+		}
+	}
+
+	return result, nil
+}
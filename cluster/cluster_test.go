@@ -0,0 +1,157 @@
+package cluster
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/go-errors/errors"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/partition"
+	"github.com/MainframeHQ/swarmer/readiness"
+)
+
+func TestWithDefaults(t *testing.T) {
+	config := WithDefaults(models.Config{Repo: "https://github.com/ethereum/go-ethereum"})
+
+	if config.Cluster != orchestrator.DefaultCluster || config.Nodes.Count != 1 || config.Topology.Type != "ring" {
+		t.Errorf("Expected the defaults of the CLI flags, got %+v", config)
+	}
+	if config.ReadyTimeout != readiness.DefaultTimeout || config.ReadyBackoff != readiness.DefaultBackoff {
+		t.Errorf("Expected the default readiness timings, got %s and %s", config.ReadyTimeout, config.ReadyBackoff)
+	}
+	if config.Path != "" {
+		t.Errorf("Expected the embedded Docker assets to be used, got %s", config.Path)
+	}
+
+	config = WithDefaults(models.Config{Cluster: "ci", Nodes: models.Nodes{Count: 3}, Topology: models.Topology{Type: "mesh"}})
+	if config.Cluster != "ci" || config.Nodes.Count != 3 || config.Topology.Type != "mesh" {
+		t.Errorf("Expected the given values to be kept, got %+v", config)
+	}
+}
+
+func TestInterruptedError(t *testing.T) {
+	err := &InterruptedError{
+		Err: errors.Errorf("context canceled"),
+		Removed: models.TeardownInfo{
+			Cluster:    "ci",
+			Containers: []string{"ci_swarm_0", "ci_swarm_1"},
+			Networks:   []string{"ci_net"},
+		},
+	}
+
+	expected := "start of cluster ci interrupted (context canceled); removed containers ci_swarm_0, ci_swarm_1; networks ci_net"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+
+	err.Removed = models.TeardownInfo{Cluster: "ci"}
+	err.RollbackErr = errors.Errorf("daemon gone")
+	if message := err.Error(); !strings.Contains(message, "nothing to remove") || !strings.Contains(message, "rolling back failed: daemon gone") {
+		t.Errorf("Unexpected message %q", message)
+	}
+}
+
+func TestNodeSpec(t *testing.T) {
+	config := WithDefaults(models.Config{Repo: "https://github.com/ethereum/go-ethereum", Add: models.Mounts{{Source: "testdata"}, {Source: "testdata", Target: "/data", Mode: "rw"}}})
+	image := nodeImage{ref: "swarmer/github-com-ethereum-go-ethereum:0123", id: "sha256:1"}
+
+	spec, err := nodeSpec(config, 0, image, "swarmer_swarm_network")
+	if err != nil {
+		t.Fatalf("Error building node spec: %s", err.Error())
+	}
+
+	if spec.Image != image.ref {
+		t.Errorf("Expected the node to run %s, got %s", image.ref, spec.Image)
+	}
+	if len(spec.Cmd) != 1 || spec.Cmd[0] != "/app/start.sh" {
+		t.Errorf("The start script shouldn't get a repo or checkout, got %v", spec.Cmd)
+	}
+
+	add, _ := filepath.Abs("testdata")
+	binds := spec.Binds[len(spec.Binds)-2:]
+	if binds[0] != add+":/swarmer:ro" {
+		t.Errorf("Expected the added directory to be mounted read-only at /swarmer, got %v", spec.Binds)
+	}
+	if binds[1] != add+":/data:rw" {
+		t.Errorf("Expected the added directory to be mounted read-write at /data, got %v", spec.Binds)
+	}
+
+	// start.sh only starts geth with GETH=true
+	geth := false
+	config.Geth = true
+	config.Nodes.Overrides = []models.NodeConfig{{Geth: &geth}}
+	spec, err = nodeSpec(config, 0, image, "swarmer_swarm_network")
+	if err != nil {
+		t.Fatalf("Error building node spec: %s", err.Error())
+	}
+	if len(spec.Env) != 1 || spec.Env[0] != "GETH=false" {
+		t.Errorf("Expected a node overriding geth to run without it, got %v", spec.Env)
+	}
+}
+
+func TestNodeLogFile(t *testing.T) {
+	tests := []struct {
+		swarmLog string
+		expected string
+	}{
+		{"swarm_log", "swarm_log_ci_0"},
+		{"swarm.log", "swarm_ci_0.log"},
+		{"/var/log/swarm.log", "/var/log/swarm_ci_0.log"},
+		{"logs/.swarm", "logs/.swarm_ci_0"},
+	}
+
+	for _, test := range tests {
+		if file := NodeLogFile(test.swarmLog, "ci", 0); file != test.expected {
+			t.Errorf("Expected the log of node 0 of %s to be %s, got %s", test.swarmLog, test.expected, file)
+		}
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	container := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{Name: "/ci_swarm_0"},
+		NetworkSettings: &types.NetworkSettings{
+			NetworkSettingsBase: types.NetworkSettingsBase{
+				Ports: nat.PortMap{"8545/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32768"}}},
+			},
+		},
+	}
+
+	if port, err := hostPort(container, "8545/tcp"); err != nil || port != "32768" {
+		t.Errorf("Expected the admin port to be published on 32768, got %q, %v", port, err)
+	}
+	if _, err := hostPort(container, "8500/tcp"); err == nil {
+		t.Error("A port that isn't published should have thrown an error...")
+	}
+	if _, err := readinessTarget(types.ContainerJSON{ContainerJSONBase: container.ContainerJSONBase}); err == nil {
+		t.Error("A container without network settings should have thrown an error...")
+	}
+}
+
+func TestScalePartition(t *testing.T) {
+	groups := []partition.Group{
+		{Name: "a", Nodes: []int{0, 3}},
+		{Name: "b", Nodes: []int{1, 2}},
+	}
+
+	grown := scalePartition(groups, 6)
+	expected := []partition.Group{
+		{Name: "a", Nodes: []int{0, 3}},
+		{Name: "b", Nodes: []int{1, 2, 4, 5}},
+	}
+	if !reflect.DeepEqual(grown, expected) {
+		t.Errorf("Expected new nodes to join the last group, got %+v", grown)
+	}
+
+	shrunk := scalePartition([]partition.Group{{Name: "a", Nodes: []int{0}}, {Name: "b", Nodes: []int{1, 2}}}, 1)
+	expected = []partition.Group{{Name: "a", Nodes: []int{0}}}
+	if !reflect.DeepEqual(shrunk, expected) {
+		t.Errorf("Expected departing nodes and empty groups to be dropped, got %+v", shrunk)
+	}
+}
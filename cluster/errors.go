@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MainframeHQ/swarmer/models"
+)
+
+// InterruptedError is returned when a start is cancelled, after the containers, volumes and
+// network it created have been removed again.
+type InterruptedError struct {
+	Err     error
+	Removed models.TeardownInfo
+	// RollbackErr is set if removing what the start created failed.
+	RollbackErr error
+}
+
+func (e *InterruptedError) Error() string {
+	message := fmt.Sprintf("start of cluster %s interrupted (%s)", e.Removed.Cluster, e.Err.Error())
+
+	var removed []string
+	for _, group := range []struct {
+		kind  string
+		names []string
+	}{
+		{"containers", e.Removed.Containers},
+		{"volumes", e.Removed.Volumes},
+		{"networks", e.Removed.Networks},
+	} {
+		if len(group.names) > 0 {
+			removed = append(removed, fmt.Sprintf("%s %s", group.kind, strings.Join(group.names, ", ")))
+		}
+	}
+
+	if len(removed) > 0 {
+		message += "; removed " + strings.Join(removed, "; ")
+	} else {
+		message += "; nothing to remove"
+	}
+	if e.RollbackErr != nil {
+		message += "; rolling back failed: " + e.RollbackErr.Error()
+	}
+
+	return message
+}
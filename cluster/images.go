@@ -0,0 +1,297 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/MainframeHQ/swarmer/assets"
+	"github.com/MainframeHQ/swarmer/images"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/util"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// nodeImage is the image a node is started from.
+type nodeImage struct {
+	ref string
+	id  string
+}
+
+// nodeImages returns the image of every node of the cluster. The published image of the config
+// is pulled if it isn't on the Docker host yet, the swarm and geth binaries put in a runtime
+// image, and the local source directory built unless its contents already are. Otherwise the
+// checkout of every node is resolved to a commit, and the image of that commit built unless it
+// already is. The config asking to recreate the nodes pulls or builds the images again.
+func (s *Cluster) nodeImages(ctx context.Context, buildLog io.Writer) ([]nodeImage, error) {
+	if s.config.Image != "" {
+		image, err := s.pull(ctx, s.config.Image, buildLog)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]nodeImage, s.config.Nodes.Count)
+		for i := range result {
+			result[i] = image
+		}
+		return result, nil
+	}
+
+	if s.config.SwarmBinary != "" {
+		image, err := s.buildBinaries(ctx, buildLog)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]nodeImage, s.config.Nodes.Count)
+		for i := range result {
+			result[i] = image
+		}
+		return result, nil
+	}
+
+	if s.config.LocalSrc != "" {
+		image, err := s.buildLocal(ctx, buildLog)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]nodeImage, s.config.Nodes.Count)
+		for i := range result {
+			result[i] = image
+		}
+		return result, nil
+	}
+
+	built := map[string]nodeImage{}
+	var result []nodeImage
+	for i := 0; i < s.config.Nodes.Count; i++ {
+		checkout := s.config.Node(i).Checkout
+
+		image, ok := built[checkout]
+		if !ok {
+			var err error
+			image, err = s.build(ctx, checkout, buildLog)
+			if err != nil {
+				return nil, err
+			}
+			built[checkout] = image
+		}
+
+		result = append(result, image)
+	}
+
+	return result, nil
+}
+
+// pull returns the image with the given reference, pulling it if it isn't on the Docker host.
+func (s *Cluster) pull(ctx context.Context, ref string, pullLog io.Writer) (nodeImage, error) {
+	id, err := s.orchestrator.ImageID(ctx, ref)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", ref, err.Error())
+	}
+
+	if id == "" || s.config.Recreate {
+		err = s.orchestrator.PullImage(ctx, ref, pullLog)
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Error pulling Swarm image %s: %s", ref, err.Error())
+		}
+
+		id, err = s.orchestrator.ImageID(ctx, ref)
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", ref, err.Error())
+		}
+	}
+
+	return nodeImage{ref: ref, id: id}, nil
+}
+
+// build returns the image of the commit checkout refers to, building it if there is none.
+func (s *Cluster) build(ctx context.Context, checkout string, buildLog io.Writer) (nodeImage, error) {
+	commit, err := s.images.Resolve(ctx, s.config.Repo, checkout)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error resolving checkout %q of %s: %s", checkout, s.config.Repo, err.Error())
+	}
+
+	tag := images.Tag(s.config.Repo, commit)
+
+	id, err := s.orchestrator.ImageID(ctx, tag)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", tag, err.Error())
+	}
+	if id != "" && !s.config.Recreate {
+		return nodeImage{ref: tag, id: id}, nil
+	}
+
+	dir, cleanup, err := assets.Dir(s.config.Path)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error writing Docker assets: %s", err.Error())
+	}
+	defer cleanup()
+
+	err = s.orchestrator.BuildImage(ctx, orchestrator.BuildSpec{
+		Context: []util.ContextDir{{Dir: dir}},
+		Tag:     tag,
+		Labels:  images.Labels(s.config.Repo, commit),
+		Args:    map[string]string{"REPO": s.config.Repo, "COMMIT": commit},
+		NoCache: s.config.Recreate,
+	}, buildLog)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error building Swarm image %s: %s", tag, err.Error())
+	}
+
+	id, err = s.orchestrator.ImageID(ctx, tag)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", tag, err.Error())
+	}
+
+	return nodeImage{ref: tag, id: id}, nil
+}
+
+// buildLocal returns the image of the current contents of the local source directory, building
+// it if there is none. The build starts from the Go build cache of the last image built from the
+// directory, so only the packages that changed are compiled again.
+func (s *Cluster) buildLocal(ctx context.Context, buildLog io.Writer) (nodeImage, error) {
+	src, err := filepath.Abs(s.config.LocalSrc)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Invalid source directory %s: %s", s.config.LocalSrc, err.Error())
+	}
+
+	source := util.ContextDir{Dir: src, Prefix: "src", Exclude: images.SourceExcludes}
+
+	hash, err := util.HashDirectory(source)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error reading source directory %s: %s", src, err.Error())
+	}
+
+	tag := images.Tag(src, hash)
+
+	id, err := s.orchestrator.ImageID(ctx, tag)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", tag, err.Error())
+	}
+	if id != "" && !s.config.Recreate {
+		return nodeImage{ref: tag, id: id}, nil
+	}
+
+	args := map[string]string{}
+	if !s.config.Recreate {
+		cached, err := s.images.List(ctx)
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Error listing Swarm images: %s", err.Error())
+		}
+		// the list is newest first
+		for _, image := range cached {
+			if image.Repo == src {
+				args["CACHE"] = image.ID
+				break
+			}
+		}
+	}
+
+	dir, cleanup, err := assets.Dir(s.config.Path)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error writing Docker assets: %s", err.Error())
+	}
+	defer cleanup()
+
+	err = s.orchestrator.BuildImage(ctx, orchestrator.BuildSpec{
+		Context:    []util.ContextDir{{Dir: dir}, source},
+		Dockerfile: "Dockerfile.SrcDir",
+		Tag:        tag,
+		Labels:     images.Labels(src, hash),
+		Args:       args,
+		NoCache:    s.config.Recreate,
+	}, buildLog)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error building Swarm image %s: %s", tag, err.Error())
+	}
+
+	id, err = s.orchestrator.ImageID(ctx, tag)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", tag, err.Error())
+	}
+
+	return nodeImage{ref: tag, id: id}, nil
+}
+
+// buildBinaries returns a runtime image holding the swarm and geth binaries of the config,
+// building it if there is none, once the binaries are found to run in it and print their
+// version.
+func (s *Cluster) buildBinaries(ctx context.Context, buildLog io.Writer) (nodeImage, error) {
+	swarm, err := filepath.Abs(s.config.SwarmBinary)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Invalid swarm binary %s: %s", s.config.SwarmBinary, err.Error())
+	}
+	geth, err := filepath.Abs(s.config.GethBinary)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Invalid geth binary %s: %s", s.config.GethBinary, err.Error())
+	}
+
+	info, err := s.dockerClient.Info(ctx)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error getting Docker host details: %s", err.Error())
+	}
+	for _, binary := range []string{swarm, geth} {
+		if err := images.CheckBinary(binary, info.Architecture); err != nil {
+			return nodeImage{}, errors.Wrap(err, 1)
+		}
+	}
+
+	hash, err := util.HashFiles(swarm, geth)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error reading binaries: %s", err.Error())
+	}
+
+	tag := images.Tag(swarm, hash)
+
+	id, err := s.orchestrator.ImageID(ctx, tag)
+	if err != nil {
+		return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", tag, err.Error())
+	}
+
+	if id == "" || s.config.Recreate {
+		dir, cleanup, err := assets.Dir(s.config.Path)
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Error writing Docker assets: %s", err.Error())
+		}
+		defer cleanup()
+
+		err = s.orchestrator.BuildImage(ctx, orchestrator.BuildSpec{
+			Files: []util.ContextFile{
+				{Path: filepath.Join(dir, "Dockerfile.Binary"), Name: "Dockerfile.Binary"},
+				{Path: filepath.Join(dir, "start.sh"), Name: "start.sh"},
+				{Path: swarm, Name: "bin/swarm"},
+				{Path: geth, Name: "bin/geth"},
+			},
+			Dockerfile: "Dockerfile.Binary",
+			Tag:        tag,
+			Labels:     images.Labels(swarm, hash),
+			NoCache:    s.config.Recreate,
+		}, buildLog)
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Error building Swarm image %s: %s", tag, err.Error())
+		}
+
+		id, err = s.orchestrator.ImageID(ctx, tag)
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Error inspecting Swarm image %s: %s", tag, err.Error())
+		}
+	}
+
+	for _, name := range []string{"swarm", "geth"} {
+		out, err := s.orchestrator.RunCommand(ctx, tag, orchestrator.HelperLabels(s.config.Cluster), []string{"/app/bin/" + name, "version"})
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Error running %s binary in image %s: %s", name, tag, err.Error())
+		}
+
+		version, err := images.ParseVersion(out, name)
+		if err != nil {
+			return nodeImage{}, errors.Errorf("Invalid %s binary: %s", name, err.Error())
+		}
+		fmt.Fprintf(buildLog, "Using %s %s\n", name, version)
+	}
+
+	return nodeImage{ref: tag, id: id}, nil
+}
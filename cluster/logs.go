@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// NodeLogFile returns the file the logs of the node at index are written to, named after the
+// swarm log with the cluster and node index inserted before its extension, e.g. swarm_log becomes
+// swarm_log_swarmer_0 and swarm.log becomes swarm_swarmer_0.log.
+func NodeLogFile(swarmLog string, cluster string, index int) string {
+	ext := filepath.Ext(swarmLog)
+	if strings.HasPrefix(filepath.Base(swarmLog), ".") && filepath.Base(swarmLog) == ext {
+		ext = ""
+	}
+
+	return fmt.Sprintf("%s_%s_%d%s", strings.TrimSuffix(swarmLog, ext), cluster, index, ext)
+}
+
+// writeLogs writes the logs so far of the node containers, keyed by node index, to the log file
+// of each node.
+func (s *Cluster) writeLogs(ctx context.Context, containerIDs map[int]string) error {
+	logsOptions := types.ContainerLogsOptions{
+		ShowStderr: true,
+		ShowStdout: true,
+	}
+
+	for index, containerID := range containerIDs {
+		stream, err := s.dockerClient.ContainerLogs(ctx, containerID, logsOptions)
+		if err != nil {
+			return errors.Errorf("Error getting container log stream: %s", err.Error())
+		}
+
+		f, err := os.Create(NodeLogFile(s.config.SwarmLog, s.config.Cluster, index))
+		if err != nil {
+			stream.Close()
+			return errors.Errorf("Error creating swarm log file on host: %s", err.Error())
+		}
+
+		_, err = stdcopy.StdCopy(f, f, stream)
+		stream.Close()
+		f.Close()
+		if err != nil {
+			return errors.Errorf("Error writing swarm logs to host machine: %s", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/netem"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/docker/docker/api/types"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// Shape replaces the impairment of the running nodes with the given indexes, or of every running
+// node if there are none, with the netem rules that apply to them. tc is run in a sidecar
+// container of the cluster image.
+func (s *Cluster) Shape(ctx context.Context, rules []models.Netem, indexes []int) ([]models.NetemStatus, error) {
+	networkName := orchestrator.NetworkName(s.config.Cluster)
+
+	running, err := s.orchestrator.ListNodes(ctx, orchestrator.Labels(s.config.Cluster))
+	if err != nil {
+		return nil, errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+	if len(running) == 0 {
+		return nil, errors.Errorf("There are no Swarm nodes running in cluster %s", s.config.Cluster)
+	}
+
+	containers := map[int]types.ContainerJSON{}
+	for _, container := range running {
+		index, ok := orchestrator.NodeIndex(container.Config.Labels)
+		if ok {
+			containers[index] = container
+		}
+	}
+
+	if len(indexes) == 0 {
+		for index := range containers {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+	}
+
+	if errs := netem.Validate(rules, maxIndex(containers)+1); len(errs) > 0 {
+		var messages []string
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return nil, errors.Errorf("Invalid netem rules: %s", strings.Join(messages, ", "))
+	}
+
+	ips := map[int]string{}
+	for index, container := range containers {
+		if endpoint, ok := container.NetworkSettings.Networks[networkName]; ok {
+			ips[index] = endpoint.IPAddress
+		}
+	}
+
+	var statuses []models.NetemStatus
+	for _, index := range indexes {
+		container, ok := containers[index]
+		if !ok {
+			return statuses, errors.Errorf("Node %d is not running", index)
+		}
+
+		commands, err := netem.Commands(netem.Rules(rules, index), ips)
+		if err != nil {
+			return statuses, errors.Errorf("Error shaping traffic of node %d: %s", index, err.Error())
+		}
+
+		_, err = s.orchestrator.RunSidecar(ctx, container.ID, container.Image, orchestrator.HelperLabels(s.config.Cluster), netem.Script(commands))
+		if err != nil {
+			return statuses, errors.Errorf("Error shaping traffic of node %d: %s", index, err.Error())
+		}
+
+		status := models.NetemStatus{Node: index, Container: strings.TrimPrefix(container.Name, "/")}
+		for _, command := range commands {
+			status.Commands = append(status.Commands, strings.Join(command, " "))
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func maxIndex(containers map[int]types.ContainerJSON) int {
+	max := -1
+	for index := range containers {
+		if index > max {
+			max = index
+		}
+	}
+
+	return max
+}
@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MainframeHQ/swarmer/admin"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/readiness"
+	"github.com/MainframeHQ/swarmer/state"
+	"github.com/MainframeHQ/swarmer/topology"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// nodeSpec returns the container spec of node i of the configured cluster, with the settings it
+// overrides applied. The container is labelled with the hash of its settings and image.
+func nodeSpec(config models.Config, i int, image nodeImage, networkName string) (orchestrator.NodeSpec, error) {
+	node := config.Node(i)
+
+	command := []string{"/app/start.sh"}
+	if node.ENS != "" {
+		command = append(command, "-e", node.ENS)
+	}
+	if node.Verbosity != nil {
+		command = append(command, "-v", strconv.Itoa(*node.Verbosity))
+	}
+	if len(node.SwarmFlags) > 0 {
+		command = append(append(command, "--"), node.SwarmFlags...)
+	}
+
+	var memory int64
+	if node.Memory != "" {
+		var err error
+		memory, err = units.RAMInBytes(node.Memory)
+		if err != nil {
+			return orchestrator.NodeSpec{}, errors.Errorf("Invalid memory limit %s for node %d: %s", node.Memory, i, err.Error())
+		}
+	}
+
+	labels := orchestrator.Labels(config.Cluster)
+	labels[orchestrator.NodeLabel] = strconv.Itoa(i)
+	labels[orchestrator.HashLabel] = state.NodeHash(config, i, image.id)
+
+	binds := []string{"/var/run/docker.sock:/var/run/docker.sock"}
+	for _, mount := range config.Add {
+		bind, err := mountBind(mount)
+		if err != nil {
+			return orchestrator.NodeSpec{}, err
+		}
+		binds = append(binds, bind)
+	}
+
+	return orchestrator.NodeSpec{
+		Name:     orchestrator.ContainerName(config.Cluster, i),
+		Image:    image.ref,
+		Network:  networkName,
+		Cmd:      command,
+		Env:      []string{"GETH=" + strconv.FormatBool(*node.Geth)},
+		Labels:   labels,
+		Binds:    binds,
+		NanoCPUs: int64(node.CPUs * 1e9),
+		Memory:   memory,
+	}, nil
+}
+
+// mountBind returns the bind of a directory added to the nodes, in the form Docker takes, with
+// the source made absolute and the defaults of the target and mode filled in.
+func mountBind(mount models.Mount) (string, error) {
+	source, err := filepath.Abs(mount.Source)
+	if err != nil {
+		return "", errors.Errorf("Invalid directory to add %s: %s", mount.Source, err.Error())
+	}
+
+	target := mount.Target
+	if target == "" {
+		target = models.DefaultMountTarget
+	}
+	mode := mount.Mode
+	if mode == "" {
+		mode = "ro"
+	}
+
+	return source + ":" + target + ":" + mode, nil
+}
+
+// readinessTarget returns the ports of a node container to probe for readiness.
+func readinessTarget(container types.ContainerJSON) (readiness.Target, error) {
+	target := readiness.Target{Name: strings.TrimPrefix(container.Name, "/")}
+
+	var err error
+	if target.AdminPort, err = hostPort(container, "8545/tcp"); err != nil {
+		return target, err
+	}
+	if target.GatewayPort, err = hostPort(container, "8500/tcp"); err != nil {
+		return target, err
+	}
+	if target.WebsocketPort, err = hostPort(container, "8546/tcp"); err != nil {
+		return target, err
+	}
+
+	return target, nil
+}
+
+// nodeInfo calls admin_nodeInfo on a running node container and adds the container details.
+func nodeInfo(ctx context.Context, adminClient admin.IClient, container types.ContainerJSON, networkName string) (models.NodeInfo, error) {
+	var info models.NodeInfo
+
+	// every port is looked up first, so that a container missing one is reported as such
+	var ports [4]string
+	for i, port := range []nat.Port{"8545/tcp", "30303/tcp", "8500/tcp", "8546/tcp"} {
+		p, err := hostPort(container, port)
+		if err != nil {
+			return info, err
+		}
+		ports[i] = p
+	}
+	adminPort := ports[0]
+
+	conn, err := adminClient.GetConnection("http://localhost:" + adminPort)
+	if err != nil {
+		return info, errors.Errorf("Error instantiating Geth admin connection over RPC: %s", err.Error())
+	}
+	defer conn.Close()
+
+	var args interface{}
+	err = conn.CallContext(ctx, &info, "admin_nodeInfo", args)
+	if err != nil {
+		return info, errors.Errorf("Unable to call nodeInfo function on geth node: %s", err.Error())
+	}
+
+	info.ContainerID = container.ID
+	info.CommPort = ports[1]
+	info.GatewayPort = ports[2]
+	info.WebsocketPort = ports[3]
+	info.AdminPort = adminPort
+	info.ContainerNames = []string{strings.TrimPrefix(container.Name, "/")}
+	if endpoint, ok := container.NetworkSettings.Networks[networkName]; ok {
+		info.IPAddress = endpoint.IPAddress
+	}
+
+	return info, nil
+}
+
+// hostPort returns the port of the host the container port is published on.
+func hostPort(container types.ContainerJSON, port nat.Port) (string, error) {
+	name := strings.TrimPrefix(container.Name, "/")
+	if container.NetworkSettings == nil {
+		return "", errors.Errorf("Container %s is not running", name)
+	}
+
+	bindings := container.NetworkSettings.Ports[port]
+	if len(bindings) == 0 {
+		return "", errors.Errorf("Container %s doesn't publish port %s", name, port)
+	}
+
+	return bindings[0].HostPort, nil
+}
+
+// peer calls the given admin method, admin_addPeer or admin_removePeer, on the From node of
+// every edge with the enode of its To node. nodes is keyed by node index.
+func peer(ctx context.Context, adminClient admin.IClient, method string, nodes map[int]models.NodeInfo, edges []topology.Edge) error {
+	var result bool
+
+	for _, edge := range edges {
+		node := nodes[edge.From]
+		other := nodes[edge.To]
+
+		conn, err := adminClient.GetConnection("http://localhost:" + node.AdminPort)
+		if err != nil {
+			return errors.Errorf("Unable to connect to geth on port %s", node.AdminPort)
+		}
+
+		splitEnode := strings.Split(other.Enode, "@")
+		enode := splitEnode[0] + "@" + other.IPAddress + ":" + other.CommPort
+
+		err = conn.CallContext(ctx, &result, method, enode)
+		conn.Close()
+		if err != nil {
+			return errors.Errorf("Unable to call %s on geth node %s with enode %s - %s", method, node.ContainerNames[0], enode, err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"strings"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/partition"
+	"github.com/docker/docker/api/types"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// Partition splits the running nodes into the groups, so that nodes can only reach the nodes of
+// their own group, or heals them if there are no groups. Any earlier partition is replaced.
+// iptables is run in a sidecar container of the cluster image, and the layout is recorded in the
+// state file for status.
+func (s *Cluster) Partition(ctx context.Context, groups []partition.Group) ([]models.PartitionStatus, error) {
+	networkName := orchestrator.NetworkName(s.config.Cluster)
+
+	running, err := s.orchestrator.ListNodes(ctx, orchestrator.Labels(s.config.Cluster))
+	if err != nil {
+		return nil, errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+	if len(running) == 0 {
+		return nil, errors.Errorf("There are no Swarm nodes running in cluster %s", s.config.Cluster)
+	}
+
+	var indexes []int
+	containers := map[int]types.ContainerJSON{}
+	ips := map[int]string{}
+	for _, container := range running {
+		index, ok := orchestrator.NodeIndex(container.Config.Labels)
+		if !ok {
+			continue
+		}
+		indexes = append(indexes, index)
+		containers[index] = container
+		if endpoint, ok := container.NetworkSettings.Networks[networkName]; ok {
+			ips[index] = endpoint.IPAddress
+		}
+	}
+
+	if groups != nil {
+		if err := partition.Validate(groups, indexes); err != nil {
+			return nil, errors.Errorf("Invalid partition groups: %s", err.Error())
+		}
+	}
+
+	var statuses []models.PartitionStatus
+	for _, index := range indexes {
+		container := containers[index]
+		status := models.PartitionStatus{Node: index, Container: strings.TrimPrefix(container.Name, "/")}
+
+		script := partition.HealScript()
+		if groups != nil {
+			script, err = partition.Script(groups, index, ips)
+			if err != nil {
+				return statuses, errors.Errorf("Error partitioning node %d: %s", index, err.Error())
+			}
+			status.Group = partition.GroupOf(groups, index)
+		}
+
+		_, err = s.orchestrator.RunSidecar(ctx, container.ID, container.Image, orchestrator.HelperLabels(s.config.Cluster), script)
+		if err != nil {
+			return statuses, errors.Errorf("Error partitioning node %d: %s", index, err.Error())
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, s.recordPartition(groups)
+}
+
+// recordPartition records the partition layout in the state file of the cluster, if there is one.
+func (s *Cluster) recordPartition(groups []partition.Group) error {
+	recorded, ok, err := s.store.Load(s.config.Cluster)
+	if err != nil || !ok {
+		return err
+	}
+
+	recorded.Partition = groups
+	if err := s.store.Save(recorded); err != nil {
+		return errors.Errorf("Error writing state of cluster %s: %s", s.config.Cluster, err.Error())
+	}
+
+	return nil
+}
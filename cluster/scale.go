@@ -0,0 +1,272 @@
+package cluster
+
+import (
+	"os"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/partition"
+	"github.com/MainframeHQ/swarmer/readiness"
+	"github.com/MainframeHQ/swarmer/topology"
+	"github.com/MainframeHQ/swarmer/validation"
+	"github.com/docker/docker/api/types"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// Scale grows or shrinks the running cluster to the given number of nodes without recreating the
+// nodes that stay. New nodes are started from their image, built or pulled if needed, and peered
+// once ready, and departing nodes are removed as peers before they are stopped. The peering of
+// the remaining nodes is then changed from the topology recorded in the state file to the topology
+// for the new node count, and the netem rules and partition of the cluster are applied again.
+func (s *Cluster) Scale(ctx context.Context, count int) error {
+
+	s.config.Nodes.Count = count
+	if len(s.config.Nodes.Overrides) > count {
+		// the settings of departing nodes no longer matter
+		s.config.Nodes.Overrides = s.config.Nodes.Overrides[:count]
+	}
+
+	err := validation.Validate(s.config, s.sources)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	networkName := orchestrator.NetworkName(s.config.Cluster)
+
+	st, ok, err := s.store.Load(s.config.Cluster)
+	if err != nil {
+		return errors.Errorf("Error reading state of cluster %s: %s", s.config.Cluster, err.Error())
+	}
+	if !ok || len(st.Nodes) == 0 {
+		return errors.Errorf("There is no state of cluster %s, use start to create it", s.config.Cluster)
+	}
+
+	running, err := s.orchestrator.ListNodes(ctx, orchestrator.Labels(s.config.Cluster))
+	if err != nil {
+		return errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+
+	// the nodes of the state file are ordered by index
+	byID := map[string]types.ContainerJSON{}
+	for _, container := range running {
+		byID[container.ID] = container
+	}
+	containers := map[int]types.ContainerJSON{}
+	for index, node := range st.Nodes {
+		container, ok := byID[node.ContainerID]
+		if !ok {
+			return errors.Errorf("Swarm node %d of cluster %s is not running, use start to restart the cluster", index, s.config.Cluster)
+		}
+		containers[index] = container
+	}
+
+	// the nodes are peered the way they were when the state was saved
+	current := st.Topology
+
+	newEdges, err := topology.Edges(s.config.Topology, count)
+	if err != nil {
+		return errors.Errorf("Error building peering topology: %s", err.Error())
+	}
+
+	var nodeImages []nodeImage
+	started := map[int]bool{}
+	for i := 0; i < count; i++ {
+		if _, ok := containers[i]; ok {
+			continue
+		}
+
+		if nodeImages == nil {
+			nodeImages, err = s.scaleImages(ctx)
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+		}
+
+		spec, err := nodeSpec(s.config, i, nodeImages[i], networkName)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		// a stopped container of the node would hold on to its name
+		_, _, err = s.orchestrator.RemoveContainers(ctx, spec.Labels, true)
+		if err != nil {
+			return errors.Errorf("Error removing stopped Swarm node: %s", err.Error())
+		}
+
+		_, err = s.orchestrator.RunNode(ctx, spec)
+		if err != nil {
+			return errors.Errorf("Error starting Swarm node: %s", err.Error())
+		}
+		started[i] = true
+	}
+
+	// the new containers are listed again to get their published ports
+	running, err = s.orchestrator.ListNodes(ctx, orchestrator.Labels(s.config.Cluster))
+	if err != nil {
+		return errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+
+	var targets []readiness.Target
+	for _, container := range running {
+		index, ok := orchestrator.NodeIndex(container.Config.Labels)
+		if !ok {
+			continue
+		}
+		if started[index] {
+			target, err := readinessTarget(container)
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+			targets = append(targets, target)
+		}
+		containers[index] = container
+	}
+	for index := range started {
+		if _, ok := containers[index]; !ok {
+			return errors.Errorf("Swarm node %s exited right after starting", orchestrator.ContainerName(s.config.Cluster, index))
+		}
+	}
+
+	if len(targets) > 0 {
+		err = s.readiness.WaitAll(ctx, targets, s.config.ReadyTimeout, s.config.ReadyBackoff)
+		if err != nil {
+			return errors.Errorf("Error waiting for Swarm nodes to become ready: %s", err.Error())
+		}
+
+		logIDs := map[int]string{}
+		for index := range started {
+			logIDs[index] = containers[index].ID
+		}
+
+		err = s.writeLogs(ctx, logIDs)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	nodes := map[int]models.NodeInfo{}
+	for index, container := range containers {
+		info, err := nodeInfo(ctx, s.adminClient, container, networkName)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+		nodes[index] = info
+	}
+
+	// departing nodes are dropped by the peers that stay before they are stopped
+	var departing []topology.Edge
+	for _, edge := range current {
+		switch {
+		case edge.From >= count && edge.To < count:
+			departing = append(departing, topology.Edge{From: edge.To, To: edge.From})
+		case edge.To >= count && edge.From < count:
+			departing = append(departing, edge)
+		}
+	}
+
+	err = peer(ctx, s.adminClient, "admin_removePeer", nodes, departing)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	for index, container := range containers {
+		if index < count {
+			continue
+		}
+
+		err = s.orchestrator.StopNode(ctx, container.ID)
+		if err != nil {
+			return errors.Errorf("Error stopping Swarm node: %s", err.Error())
+		}
+		delete(nodes, index)
+	}
+
+	added, removed := topology.Diff(current, newEdges)
+
+	var stale []topology.Edge
+	for _, edge := range removed {
+		if edge.From < count && edge.To < count {
+			stale = append(stale, edge)
+		}
+	}
+
+	err = peer(ctx, s.adminClient, "admin_removePeer", nodes, stale)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	err = peer(ctx, s.adminClient, "admin_addPeer", nodes, added)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	s.nodes = nil
+	for i := 0; i < count; i++ {
+		s.nodes = append(s.nodes, nodes[i])
+	}
+
+	err = s.save(newEdges)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	// the rules are applied to every node again, as the peers they shape may have changed
+	if len(s.config.Netem) > 0 {
+		_, err = s.Shape(ctx, s.config.Netem, nil)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	if len(st.Partition) > 0 {
+		_, err = s.Partition(ctx, scalePartition(st.Partition, count))
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	return nil
+}
+
+// scalePartition returns the partition groups for the new node count. Departing nodes are dropped
+// from their group, and new nodes join the last group.
+func scalePartition(groups []partition.Group, count int) []partition.Group {
+	seen := map[int]bool{}
+	var scaled []partition.Group
+	for _, group := range groups {
+		kept := partition.Group{Name: group.Name}
+		for _, index := range group.Nodes {
+			seen[index] = true
+			if index < count {
+				kept.Nodes = append(kept.Nodes, index)
+			}
+		}
+		if len(kept.Nodes) > 0 {
+			scaled = append(scaled, kept)
+		}
+	}
+	if len(scaled) == 0 {
+		return nil
+	}
+
+	last := len(scaled) - 1
+	for index := 0; index < count; index++ {
+		if !seen[index] {
+			scaled[last].Nodes = append(scaled[last].Nodes, index)
+		}
+	}
+
+	return scaled
+}
+
+// scaleImages returns the image of every node, appending the build output to the docker log.
+func (s *Cluster) scaleImages(ctx context.Context) ([]nodeImage, error) {
+	buildLog, err := os.OpenFile(s.config.DockerLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Errorf("Error opening docker log file on host: %s", err.Error())
+	}
+	defer buildLog.Close()
+
+	return s.nodeImages(ctx, buildLog)
+}
@@ -0,0 +1,229 @@
+package cluster
+
+import (
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/partition"
+	"github.com/MainframeHQ/swarmer/readiness"
+	"github.com/MainframeHQ/swarmer/state"
+	"github.com/MainframeHQ/swarmer/topology"
+	"github.com/docker/docker/api/types"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// healthTimeout is how long the nodes of a running cluster get to answer before it is started
+// again.
+const healthTimeout = 5 * time.Second
+
+// Running returns the nodes recorded in the state file of the cluster, along with the partition
+// group they were last put in, or false if there is no state or not every recorded node is still
+// running in its container.
+func (s *Cluster) Running(ctx context.Context) ([]models.NodeInfo, bool, error) {
+	st, ok, err := s.store.Load(s.config.Cluster)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	running, err := s.running(ctx, st)
+	if err != nil || !running {
+		return nil, false, err
+	}
+
+	for i := range st.Nodes {
+		st.Nodes[i].Partition = partition.GroupOf(st.Partition, i)
+	}
+
+	return st.Nodes, true, nil
+}
+
+// Status returns the details of the running nodes of the cluster, ordered by node index. The
+// nodes recorded in the state file are returned if they are all still running, otherwise every
+// running node is asked for its details. Nodes still running in the container recorded for them
+// get the partition group recorded in the state file.
+func (s *Cluster) Status(ctx context.Context) ([]models.NodeInfo, error) {
+	recorded, ok, err := s.Running(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+	if ok {
+		return recorded, nil
+	}
+
+	st, _, err := s.store.Load(s.config.Cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	running, err := s.orchestrator.ListNodes(ctx, orchestrator.Labels(s.config.Cluster))
+	if err != nil {
+		return nil, errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+
+	networkName := orchestrator.NetworkName(s.config.Cluster)
+	var nodes []models.NodeInfo
+	for _, container := range running {
+		index, ok := orchestrator.NodeIndex(container.Config.Labels)
+		if !ok {
+			continue
+		}
+
+		info, err := nodeInfo(ctx, s.adminClient, container, networkName)
+		if err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+		if index < len(st.Nodes) && st.Nodes[index].ContainerID == container.ID {
+			info.Partition = partition.GroupOf(st.Partition, index)
+		}
+
+		nodes = append(nodes, info)
+	}
+
+	return nodes, nil
+}
+
+// running returns whether every node of the state, and no other, is running in its container.
+func (s *Cluster) running(ctx context.Context, st state.State) (bool, error) {
+	containers, err := s.orchestrator.ListNodes(ctx, orchestrator.Labels(st.Cluster))
+	if err != nil {
+		return false, errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+	if len(containers) != len(st.Nodes) {
+		return false, nil
+	}
+
+	ids := map[string]bool{}
+	for _, container := range containers {
+		ids[container.ID] = true
+	}
+	for _, node := range st.Nodes {
+		if !ids[node.ContainerID] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// reuse adopts the running nodes of the cluster, returning true, if there are as many as the
+// config asks for, each was started from its image with the settings it has now, and they are
+// all healthy. The peering recorded in the state file is changed to the topology of the config,
+// and the netem rules of the config are applied again, replacing any shaping done with netem set
+// or clear, as neither needs the nodes to be recreated.
+func (s *Cluster) reuse(ctx context.Context, nodeImages []nodeImage) (bool, error) {
+	count := s.config.Nodes.Count
+
+	running, err := s.orchestrator.ListNodes(ctx, orchestrator.Labels(s.config.Cluster))
+	if err != nil {
+		return false, errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+	if len(running) != count {
+		return false, nil
+	}
+
+	containers := map[int]types.ContainerJSON{}
+	var targets []readiness.Target
+	for _, container := range running {
+		index, ok := orchestrator.NodeIndex(container.Config.Labels)
+		if !ok || index >= count || container.Config.Labels[orchestrator.HashLabel] != state.NodeHash(s.config, index, nodeImages[index].id) {
+			return false, nil
+		}
+		if _, ok := containers[index]; ok {
+			// two containers of the same node leave another node missing
+			return false, nil
+		}
+		containers[index] = container
+		target, err := readinessTarget(container)
+		if err != nil {
+			return false, nil
+		}
+		targets = append(targets, target)
+	}
+
+	if s.readiness.WaitAll(ctx, targets, healthTimeout, readiness.DefaultBackoff) != nil {
+		return false, nil
+	}
+
+	networkName := orchestrator.NetworkName(s.config.Cluster)
+	nodes := map[int]models.NodeInfo{}
+	s.nodes = nil
+	for index := 0; index < count; index++ {
+		info, err := nodeInfo(ctx, s.adminClient, containers[index], networkName)
+		if err != nil {
+			return false, errors.Wrap(err, 1)
+		}
+		nodes[index] = info
+		s.nodes = append(s.nodes, info)
+	}
+
+	edges, err := topology.Edges(s.config.Topology, count)
+	if err != nil {
+		return false, errors.Errorf("Error building peering topology: %s", err.Error())
+	}
+
+	// without a record of the nodes, their peering is unknown, so every edge is added
+	var current []topology.Edge
+	recorded, ok, err := s.store.Load(s.config.Cluster)
+	if err == nil && ok && sameContainers(recorded.Nodes, s.nodes) {
+		current = recorded.Topology
+	}
+
+	added, removed := topology.Diff(current, edges)
+	err = peer(ctx, s.adminClient, "admin_removePeer", nodes, removed)
+	if err != nil {
+		return false, errors.Wrap(err, 1)
+	}
+	err = peer(ctx, s.adminClient, "admin_addPeer", nodes, added)
+	if err != nil {
+		return false, errors.Wrap(err, 1)
+	}
+
+	// the shaping of the nodes isn't recorded, so the rules are applied even if they are the same,
+	// and no rules clear it
+	_, err = s.Shape(ctx, s.config.Netem, nil)
+	if err != nil {
+		return false, errors.Wrap(err, 1)
+	}
+
+	return true, s.save(edges)
+}
+
+// save records the current nodes of the cluster, peered with the given edges, in its state file.
+// The partition layout recorded earlier is kept if the nodes are still the same containers.
+func (s *Cluster) save(edges []topology.Edge) error {
+	current := state.State{
+		Cluster:   s.config.Cluster,
+		Config:    s.config,
+		StartedAt: time.Now().UTC(),
+		Nodes:     s.nodes,
+		Topology:  edges,
+	}
+
+	previous, ok, err := s.store.Load(s.config.Cluster)
+	if err == nil && ok && sameContainers(previous.Nodes, current.Nodes) {
+		current.StartedAt = previous.StartedAt
+		current.Partition = previous.Partition
+	}
+
+	err = s.store.Save(current)
+	if err != nil {
+		return errors.Errorf("Error writing state of cluster %s: %s", s.config.Cluster, err.Error())
+	}
+
+	return nil
+}
+
+func sameContainers(a []models.NodeInfo, b []models.NodeInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ContainerID != b[i].ContainerID {
+			return false
+		}
+	}
+
+	return true
+}
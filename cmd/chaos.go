@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/chaos"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IChaosCommand is the interface to implement for the chaos commands.
+type IChaosCommand interface {
+	Inject(c *cli.Context) error
+	Run(c *cli.Context) error
+}
+
+// ChaosCommand is the struct for this implementation of IChaosCommand.
+type ChaosCommand struct {
+	config models.Config
+	chaos  chaos.IChaos
+}
+
+// GetChaosCommand returns a pointer to a new instance of this implementation of IChaosCommand.
+func GetChaosCommand(c models.Config, ch chaos.IChaos) *ChaosCommand {
+	var s = ChaosCommand{
+		config: c,
+		chaos:  ch,
+	}
+
+	return &s
+}
+
+// Inject applies the fault named by the command to the nodes given by --node and --random, and
+// prints a JSON event for each node.
+func (s *ChaosCommand) Inject(c *cli.Context) error {
+
+	action := c.Command.Name
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	nodes, err := s.chaos.Nodes(ctx, s.config.Cluster)
+	if err != nil {
+		return errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+
+	seed := c.Int64("seed")
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	selected, err := chaos.Select(nodes, c.IntSlice("node"), c.Int("random"), rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	if c.Int("random") == 0 {
+		seed = 0
+	}
+
+	failed, err := chaos.Inject(ctx, s.chaos, s.config.Cluster, action, selected, seed, os.Stdout)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	if failed > 0 {
+		return errors.Errorf("Unable to %s %d of %d nodes", action, failed, len(selected))
+	}
+
+	return nil
+}
+
+// Run applies the fault plan given as the argument over time, printing a JSON event for every
+// action.
+func (s *ChaosCommand) Run(c *cli.Context) error {
+
+	if c.NArg() != 1 {
+		return errors.Errorf("Usage: swarmer chaos run <plan.yml>")
+	}
+
+	plan, err := chaos.ParsePlan(c.Args().First())
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	return chaos.RunPlan(ctx, s.chaos, s.config.Cluster, plan, os.Stdout)
+}
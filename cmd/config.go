@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/MainframeHQ/swarmer/util"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IConfigCommand is the interface to implement for the config command.
+type IConfigCommand interface {
+	Show(c *cli.Context) error
+}
+
+// ConfigCommand is the struct for this implementation of IConfigCommand.
+type ConfigCommand struct {
+	config  models.Config
+	sources map[string]string
+}
+
+// GetConfigCommand returns a pointer to a new instance of this implementation of IConfigCommand.
+func GetConfigCommand(c models.Config, sources map[string]string) *ConfigCommand {
+	var s = ConfigCommand{
+		config:  c,
+		sources: sources,
+	}
+
+	return &s
+}
+
+// Show prints every effective config value along with where it came from.
+func (s *ConfigCommand) Show(c *cli.Context) error {
+
+	if err := output.Validate(s.config.Output); err != nil {
+		return err
+	}
+
+	var values []models.ConfigValue
+	for _, key := range util.ConfigKeys() {
+		value := util.ConfigValue(s.config, key)
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+
+		values = append(values, models.ConfigValue{
+			Key:    key,
+			Value:  value,
+			Source: s.sources[key],
+		})
+	}
+
+	return output.Value(os.Stdout, s.config.Output, values)
+}
+
+// LoadConfig merges, from lowest to highest precedence, the built-in defaults, the config file,
+// DEVCLUSTER_* environment variables and explicitly given flags. flagConfig is the config the
+// global flags were parsed into, and any of its values not bound to a flag are kept as defaults.
+// c may be the context of any command, the global flags are looked up from the app. It returns
+// the merged config along with the source of every value. If the config file can't be parsed,
+// the error is returned along with the config merged from the other sources.
+func LoadConfig(c *cli.Context, flagConfig *models.Config, parser util.IConfigParser) (models.Config, map[string]string, error) {
+	// --add is a string slice flag so that it can be repeated, which the cli can't parse into the
+	// config, so its mounts are parsed here
+	add, err := models.ParseMounts(c.GlobalStringSlice("add"))
+	if err != nil {
+		return *flagConfig, nil, errors.Errorf("Error parsing --add: %s", err.Error())
+	}
+	flagConfig.Add = add
+
+	defaults := util.ConfigLayer{Config: *flagConfig, Sources: map[string]string{}}
+	env := util.ConfigLayer{Config: *flagConfig, Sources: map[string]string{}}
+	flags := util.ConfigLayer{Config: *flagConfig, Sources: map[string]string{}}
+
+	for _, key := range util.ConfigKeys() {
+		defaults.Sources[key] = util.SourceDefault
+	}
+
+	root := c
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+
+	for _, flag := range root.App.Flags {
+		binding, ok := getFlagBinding(flag, flagConfig)
+		if !ok {
+			continue
+		}
+
+		key := util.ConfigKeyOf(flagConfig, binding.destination)
+		if key == "" {
+			continue
+		}
+
+		util.SetConfigValue(&defaults.Config, key, binding.value)
+
+		envValue, envSet := os.LookupEnv(binding.envVar)
+		if envSet && binding.envVar != "" && envMatches(binding.destination, envValue) {
+			env.Sources[key] = "env " + binding.envVar
+		} else if globalIsSet(c, binding.names) {
+			flags.Sources[key] = "flag --" + binding.names[0]
+		}
+	}
+
+	layers := []util.ConfigLayer{defaults}
+
+	path := flagConfig.Config
+	if path == "" {
+		for _, file := range util.DefaultConfigFiles {
+			if _, err := os.Stat(file); err == nil {
+				path = file
+				break
+			}
+		}
+	}
+
+	var fileErr error
+	if path != "" {
+		file, err := parser.ParseConfigLayer(path)
+		if err != nil {
+			fileErr = errors.Errorf("Error parsing config %s: %s", path, err.Error())
+		} else {
+			layers = append(layers, file)
+		}
+	}
+
+	layers = append(layers, env, flags)
+
+	config, sources := util.MergeConfig(layers...)
+
+	return config, sources, fileErr
+}
+
+// flagBinding describes a global flag that sets a config value.
+type flagBinding struct {
+	names       []string
+	envVar      string
+	destination interface{}
+	value       interface{}
+}
+
+func getFlagBinding(flag cli.Flag, config *models.Config) (flagBinding, bool) {
+	var name string
+	var b flagBinding
+
+	switch f := flag.(type) {
+	case cli.StringFlag:
+		name, b = f.Name, flagBinding{envVar: f.EnvVar, destination: f.Destination, value: f.Value}
+	case cli.IntFlag:
+		name, b = f.Name, flagBinding{envVar: f.EnvVar, destination: f.Destination, value: f.Value}
+	case cli.Int64Flag:
+		name, b = f.Name, flagBinding{envVar: f.EnvVar, destination: f.Destination, value: f.Value}
+	case cli.DurationFlag:
+		name, b = f.Name, flagBinding{envVar: f.EnvVar, destination: f.Destination, value: f.Value}
+	case cli.BoolFlag:
+		name, b = f.Name, flagBinding{envVar: f.EnvVar, destination: f.Destination, value: false}
+	case cli.StringSliceFlag:
+		// the only string slice flag is --add, which LoadConfig parses into the config
+		name, b = f.Name, flagBinding{envVar: f.EnvVar, destination: &config.Add, value: models.Mounts(nil)}
+	default:
+		return b, false
+	}
+
+	for _, n := range strings.Split(name, ",") {
+		b.names = append(b.names, strings.TrimSpace(n))
+	}
+
+	return b, true
+}
+
+// globalIsSet reports whether the global flag was given by any of its names. The cli marks every
+// name of a flag as set when one is, except for string slices.
+func globalIsSet(c *cli.Context, names []string) bool {
+	for _, name := range names {
+		if c.GlobalIsSet(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// envMatches reports whether the value parsed into destination is the given environment value,
+// in which case the value came from the environment rather than the command line.
+func envMatches(destination interface{}, env string) bool {
+	switch d := destination.(type) {
+	case *string:
+		return *d == env
+	case *int:
+		v, err := strconv.ParseInt(env, 0, 64)
+		return err == nil && int64(*d) == v
+	case *int64:
+		v, err := strconv.ParseInt(env, 0, 64)
+		return err == nil && *d == v
+	case *bool:
+		v, err := strconv.ParseBool(env)
+		return err == nil && *d == v
+	case *time.Duration:
+		v, err := time.ParseDuration(env)
+		return err == nil && *d == v
+	case *models.Mounts:
+		var specs []string
+		for _, spec := range strings.Split(env, ",") {
+			specs = append(specs, strings.TrimSpace(spec))
+		}
+		v, err := models.ParseMounts(specs)
+		return err == nil && d.String() == v.String()
+	}
+
+	return false
+}
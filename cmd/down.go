@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/MainframeHQ/swarmer/cluster"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IDownCommand is the interface to implement for the down command.
+type IDownCommand interface {
+	Down(c *cli.Context) error
+}
+
+// DownCommand is the struct for this implementation of IDownCommand.
+type DownCommand struct {
+	config  models.Config
+	cluster cluster.ICluster
+}
+
+// GetDownCommand returns a pointer to a new instance of this implementation of IDownCommand.
+func GetDownCommand(c models.Config, cl cluster.ICluster) *DownCommand {
+	var d = DownCommand{
+		config:  c,
+		cluster: cl,
+	}
+
+	return &d
+}
+
+// Down removes the containers, volumes, network and images of the cluster and shows what was
+// removed in the configured output format.
+func (d *DownCommand) Down(c *cli.Context) error {
+
+	if err := output.Validate(d.config.Output); err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	result, err := d.cluster.Teardown(ctx, c.Bool("keep-volumes"), c.Bool("keep-images"))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	err = output.Value(os.Stdout, d.config.Output, result)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
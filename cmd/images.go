@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MainframeHQ/swarmer/images"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IImagesCommand is the interface to implement for the images commands.
+type IImagesCommand interface {
+	List(c *cli.Context) error
+	Prune(c *cli.Context) error
+}
+
+// ImagesCommand is the struct for this implementation of IImagesCommand.
+type ImagesCommand struct {
+	config models.Config
+	images images.IImages
+}
+
+// GetImagesCommand returns a pointer to a new instance of this implementation of IImagesCommand.
+func GetImagesCommand(c models.Config, i images.IImages) *ImagesCommand {
+	var s = ImagesCommand{
+		config: c,
+		images: i,
+	}
+
+	return &s
+}
+
+// List shows the Swarm images built by swarmer in the configured output format.
+func (s *ImagesCommand) List(c *cli.Context) error {
+
+	if err := output.Validate(s.config.Output); err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	result, err := s.images.List(ctx)
+	if err != nil {
+		return errors.Errorf("Error listing Swarm images: %s", err.Error())
+	}
+
+	if len(result) == 0 {
+		fmt.Fprintln(os.Stderr, "There are no Swarm images.")
+		return nil
+	}
+
+	return output.Value(os.Stdout, s.config.Output, result)
+}
+
+// Prune removes the Swarm images built by swarmer that no container uses and shows the IDs of
+// the removed images in the configured output format.
+func (s *ImagesCommand) Prune(c *cli.Context) error {
+
+	if err := output.Validate(s.config.Output); err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	removed, err := s.images.Prune(ctx)
+	if err != nil {
+		return errors.Errorf("Error removing Swarm images: %s", err.Error())
+	}
+
+	if removed == nil {
+		removed = []string{}
+	}
+
+	return output.Value(os.Stdout, s.config.Output, removed)
+}
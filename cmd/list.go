@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IListCommand is the interface to implement for the list command.
+type IListCommand interface {
+	List(c *cli.Context) error
+}
+
+// ListCommand is the struct for this implementation of IListCommand.
+type ListCommand struct {
+	config       models.Config
+	dockerClient *client.Client
+}
+
+// GetListCommand returns a pointer to a new instance of this implementation of IListCommand.
+func GetListCommand(c models.Config, d *client.Client) *ListCommand {
+	var l = ListCommand{
+		config:       c,
+		dockerClient: d,
+	}
+
+	return &l
+}
+
+// List shows every cluster with containers on the Docker host in the configured output format.
+func (l *ListCommand) List(c *cli.Context) error {
+
+	if err := output.Validate(l.config.Output); err != nil {
+		return err
+	}
+
+	var options types.ContainerListOptions
+
+	options.All = true
+	options.Filters = filters.NewArgs()
+	options.Filters.Add("label", orchestrator.DomainLabel+"="+orchestrator.DomainValue)
+	options.Filters.Add("label", orchestrator.ClusterLabel)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	containers, err := l.dockerClient.ContainerList(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	clusters := map[string]*models.ClusterInfo{}
+	for _, container := range containers {
+		name := container.Labels[orchestrator.ClusterLabel]
+
+		cluster, ok := clusters[name]
+		if !ok {
+			cluster = &models.ClusterInfo{
+				Name:    name,
+				Network: orchestrator.NetworkName(name),
+			}
+			clusters[name] = cluster
+		}
+
+		cluster.Nodes++
+		if container.State == "running" {
+			cluster.Running++
+		}
+	}
+
+	if len(clusters) == 0 {
+		fmt.Fprintln(os.Stderr, "There are no Swarm clusters.")
+		return nil
+	}
+
+	var results []models.ClusterInfo
+	for _, cluster := range clusters {
+		results = append(results, *cluster)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	err = output.Value(os.Stdout, l.config.Output, results)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
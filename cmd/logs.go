@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/MainframeHQ/swarmer/logs"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// ILogsCommand is the interface to implement for the logs command.
+type ILogsCommand interface {
+	Logs(c *cli.Context) error
+}
+
+// LogsCommand is the struct for this implementation of ILogsCommand.
+type LogsCommand struct {
+	config       models.Config
+	dockerClient *client.Client
+}
+
+// GetLogsCommand returns a pointer to a new instance of this implementation of ILogsCommand.
+func GetLogsCommand(c models.Config, d *client.Client) *LogsCommand {
+	var s = LogsCommand{
+		config:       c,
+		dockerClient: d,
+	}
+
+	return &s
+}
+
+// Logs shows the logs of the nodes given as arguments by index, or of every node, each line
+// prefixed with its node and the lines of all nodes in the order of their timestamps.
+func (s *LogsCommand) Logs(c *cli.Context) error {
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	var options types.ContainerListOptions
+
+	options.All = true
+	options.Filters = filters.NewArgs()
+	options.Filters.Add("label", orchestrator.DomainLabel+"="+orchestrator.DomainValue)
+	options.Filters.Add("label", orchestrator.ClusterLabel+"="+s.config.Cluster)
+
+	containers, err := s.dockerClient.ContainerList(ctx, options)
+	if err != nil {
+		return errors.Errorf("Error listing Swarm nodes: %s", err.Error())
+	}
+
+	containerIDs := map[int]string{}
+	for _, container := range containers {
+		if index, ok := orchestrator.NodeIndex(container.Labels); ok {
+			containerIDs[index] = container.ID
+		}
+	}
+	if len(containerIDs) == 0 {
+		return errors.Errorf("There are no Swarm nodes in cluster %s", s.config.Cluster)
+	}
+
+	var indexes []int
+	for _, arg := range c.Args() {
+		index, err := strconv.Atoi(arg)
+		if err != nil {
+			return errors.Errorf("Invalid node index %s", arg)
+		}
+		if _, ok := containerIDs[index]; !ok {
+			return errors.Errorf("There is no node %d in cluster %s", index, s.config.Cluster)
+		}
+		indexes = append(indexes, index)
+	}
+	if len(indexes) == 0 {
+		for index := 0; len(indexes) < len(containerIDs); index++ {
+			if _, ok := containerIDs[index]; ok {
+				indexes = append(indexes, index)
+			}
+		}
+	}
+
+	logsOptions := types.ContainerLogsOptions{
+		ShowStderr: true,
+		ShowStdout: true,
+		Timestamps: true,
+		Follow:     c.Bool("follow"),
+		Since:      c.String("since"),
+		Tail:       c.String("tail"),
+	}
+
+	var sources []logs.Source
+	for _, index := range indexes {
+		stream, err := s.dockerClient.ContainerLogs(ctx, containerIDs[index], logsOptions)
+		if err != nil {
+			return errors.Errorf("Error getting container log stream: %s", err.Error())
+		}
+		defer stream.Close()
+
+		// every line Docker writes with its timestamp is a frame of its own, so stdout and stderr
+		// can share the pipe without splitting lines
+		pr, pw := io.Pipe()
+		defer pr.Close()
+		go func(stream io.Reader) {
+			_, err := stdcopy.StdCopy(pw, pw, stream)
+			pw.CloseWithError(err)
+		}(stream)
+
+		sources = append(sources, logs.Source{Node: index, Reader: pr})
+	}
+
+	err = logs.Merge(os.Stdout, sources, logs.DefaultWindow, !c.Bool("no-color") && isTerminal(os.Stdout))
+	if err != nil && ctx.Err() == nil {
+		return errors.Errorf("Error reading the logs of the Swarm nodes: %s", err.Error())
+	}
+
+	return nil
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
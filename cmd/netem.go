@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/MainframeHQ/swarmer/cluster"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// INetemCommand is the interface to implement for the netem commands.
+type INetemCommand interface {
+	Apply(c *cli.Context) error
+	Set(c *cli.Context) error
+	Clear(c *cli.Context) error
+}
+
+// NetemCommand is the struct for this implementation of INetemCommand.
+type NetemCommand struct {
+	config  models.Config
+	cluster cluster.ICluster
+}
+
+// GetNetemCommand returns a pointer to a new instance of this implementation of INetemCommand.
+func GetNetemCommand(c models.Config, cl cluster.ICluster) *NetemCommand {
+	var s = NetemCommand{
+		config:  c,
+		cluster: cl,
+	}
+
+	return &s
+}
+
+// Apply shapes the traffic of every node according to the netem rules of the config, clearing
+// the impairment of nodes no rule applies to.
+func (s *NetemCommand) Apply(c *cli.Context) error {
+	return s.shape(c, s.config.Netem, nil)
+}
+
+// Set replaces the impairment of the nodes given by --node, or of every node, with the single
+// rule given by the flags.
+func (s *NetemCommand) Set(c *cli.Context) error {
+	rule := models.Netem{
+		Nodes:  c.IntSlice("node"),
+		Peers:  c.IntSlice("peer"),
+		Delay:  c.Duration("delay"),
+		Jitter: c.Duration("jitter"),
+		Loss:   c.Float64("loss"),
+		Rate:   c.String("rate"),
+	}
+
+	return s.shape(c, []models.Netem{rule}, rule.Nodes)
+}
+
+// Clear removes the impairment of the nodes given by --node, or of every node.
+func (s *NetemCommand) Clear(c *cli.Context) error {
+	return s.shape(c, nil, c.IntSlice("node"))
+}
+
+// shape applies rules to the nodes with the given indexes, or to every node if there are none.
+func (s *NetemCommand) shape(c *cli.Context, rules []models.Netem, indexes []int) error {
+
+	if err := output.Validate(s.config.Output); err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	statuses, err := s.cluster.Shape(ctx, rules, indexes)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return output.Value(os.Stdout, s.config.Output, statuses)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/MainframeHQ/swarmer/cluster"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/MainframeHQ/swarmer/partition"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IPartitionCommand is the interface to implement for the partition and heal commands.
+type IPartitionCommand interface {
+	Partition(c *cli.Context) error
+	Heal(c *cli.Context) error
+}
+
+// PartitionCommand is the struct for this implementation of IPartitionCommand.
+type PartitionCommand struct {
+	config  models.Config
+	cluster cluster.ICluster
+}
+
+// GetPartitionCommand returns a pointer to a new instance of this implementation of IPartitionCommand.
+func GetPartitionCommand(c models.Config, cl cluster.ICluster) *PartitionCommand {
+	var s = PartitionCommand{
+		config:  c,
+		cluster: cl,
+	}
+
+	return &s
+}
+
+// Partition splits the running nodes into the groups given by --groups, so that nodes can only
+// reach the nodes of their own group. Any earlier partition is replaced.
+func (s *PartitionCommand) Partition(c *cli.Context) error {
+
+	groups, err := partition.ParseGroups(c.String("groups"))
+	if err != nil {
+		return errors.Errorf("Invalid --groups: %s", err.Error())
+	}
+
+	return s.apply(groups)
+}
+
+// Heal removes the partition, so that every node can reach every other node again.
+func (s *PartitionCommand) Heal(c *cli.Context) error {
+	return s.apply(nil)
+}
+
+// apply partitions every running node into groups, or heals them if there are none.
+func (s *PartitionCommand) apply(groups []partition.Group) error {
+
+	if err := output.Validate(s.config.Output); err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	statuses, err := s.cluster.Partition(ctx, groups)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return output.Value(os.Stdout, s.config.Output, statuses)
+}
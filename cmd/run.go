@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/MainframeHQ/swarmer/cluster"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IRunCommand is the interface to implement for the run command.
+type IRunCommand interface {
+	Run(c *cli.Context) error
+}
+
+// RunCommand is the struct for this implementation of IRunCommand.
+type RunCommand struct {
+	config  models.Config
+	cluster cluster.ICluster
+}
+
+// GetRunCommand returns a pointer to a new instance of this implementation of IRunCommand.
+func GetRunCommand(c models.Config, cl cluster.ICluster) *RunCommand {
+	var s = RunCommand{
+		config:  c,
+		cluster: cl,
+	}
+
+	return &s
+}
+
+// Run starts the cluster, runs the command given after -- with the details of the nodes in its
+// environment, and tears the cluster down again, exiting with the exit status of the command.
+// The cluster is also torn down when swarmer is interrupted, and kept if the command or the
+// start fails and --keep-on-failure is given.
+func (s *RunCommand) Run(c *cli.Context) error {
+
+	args := c.Args()
+	if len(args) == 0 {
+		return errors.Errorf("Usage: swarmer run [--keep-on-failure] -- <command> [arguments...]")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var child *exec.Cmd
+	interrupted := false
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				mu.Lock()
+				interrupted = true
+				if child != nil && child.Process != nil {
+					child.Process.Signal(sig)
+				} else {
+					cancel()
+				}
+				mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	code, err := s.run(ctx, args, func(cmd *exec.Cmd) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if interrupted {
+			return errors.Errorf("Interrupted before running %s", args[0])
+		}
+		child = cmd
+
+		return cmd.Start()
+	})
+
+	mu.Lock()
+	keep := c.Bool("keep-on-failure") && !interrupted && (err != nil || code != 0)
+	mu.Unlock()
+
+	if keep {
+		fmt.Fprintf(os.Stderr, "Keeping cluster %s for debugging, remove it with: swarmer --cluster %s down\n", s.config.Cluster, s.config.Cluster)
+	} else if _, teardownErr := s.cluster.Teardown(context.Background(), false, true); teardownErr != nil && err == nil {
+		err = teardownErr
+	}
+
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	if code != 0 {
+		return cli.NewExitError("", code)
+	}
+
+	return nil
+}
+
+// run starts the cluster and runs the command in args with start, returning its exit status.
+func (s *RunCommand) run(ctx context.Context, args []string, start func(*exec.Cmd) error) (int, error) {
+	err := s.cluster.Start(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), output.Environment(s.cluster.Nodes())...)
+
+	if err := start(cmd); err != nil {
+		return 0, errors.Errorf("Error running %s: %s", args[0], err.Error())
+	}
+
+	err = cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			if status.Signaled() {
+				return 128 + int(status.Signal()), nil
+			}
+			return status.ExitStatus(), nil
+		}
+		return 1, nil
+	}
+	if err != nil {
+		return 0, errors.Errorf("Error running %s: %s", args[0], err.Error())
+	}
+
+	return 0, nil
+}
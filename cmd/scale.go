@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/MainframeHQ/swarmer/cluster"
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/go-errors/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// IScaleCommand is the interface to implement for the scale command.
+type IScaleCommand interface {
+	Scale(c *cli.Context) error
+}
+
+// ScaleCommand is the struct for this implementation of IScaleCommand.
+type ScaleCommand struct {
+	config  models.Config
+	cluster cluster.ICluster
+}
+
+// GetScaleCommand returns a pointer to a new instance of this implementation of IScaleCommand.
+func GetScaleCommand(c models.Config, cl cluster.ICluster) *ScaleCommand {
+	var s = ScaleCommand{
+		config:  c,
+		cluster: cl,
+	}
+
+	return &s
+}
+
+// Scale grows or shrinks a running cluster to the given number of nodes and shows the details of
+// the resulting nodes in the configured output format.
+func (s *ScaleCommand) Scale(c *cli.Context) error {
+
+	count, err := strconv.Atoi(c.Args().First())
+	if err != nil || c.NArg() != 1 {
+		return errors.Errorf("Usage: swarmer scale <number of nodes>")
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	err = s.cluster.Scale(ctx, count)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	err = output.Nodes(os.Stdout, s.config.Output, s.cluster.Nodes())
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/net/context"
+)
+
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM, so a command can stop
+// what it is doing and clean up. Calling cancel stops listening for the signals.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+
+	return ctx, cancel
+}
@@ -2,12 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"golang.org/x/net/context"
 
+	"github.com/MainframeHQ/swarmer/cluster"
 	"github.com/MainframeHQ/swarmer/models"
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/client"
+	"github.com/go-errors/errors"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -18,15 +16,15 @@ type IStopCommand interface {
 
 // StopCommand is the struct for this implementation of IStopCommand.
 type StopCommand struct {
-	config       models.Config
-	dockerClient *client.Client
+	config  models.Config
+	cluster cluster.ICluster
 }
 
 // GetStopCommand returns a pointer to a new instance of this implementation of IStopCommand.
-func GetStopCommand(c models.Config, d *client.Client) *StopCommand {
+func GetStopCommand(c models.Config, cl cluster.ICluster) *StopCommand {
 	var s = StopCommand{
-		config:       c,
-		dockerClient: d,
+		config:  c,
+		cluster: cl,
 	}
 
 	return &s
@@ -35,26 +33,15 @@ func GetStopCommand(c models.Config, d *client.Client) *StopCommand {
 // Stop is the command that stops the Swarm nodes.
 func (s *StopCommand) Stop(c *cli.Context) error {
 
-	var options types.ContainerListOptions
+	ctx, cancel := signalContext()
+	defer cancel()
 
-	options.All = true
-	options.Filters = filters.NewArgs()
-	options.Filters.Add("status", "running")
-	options.Filters.Add("label", "org.mfhq.domain=swarm")
-
-	containers, err := s.dockerClient.ContainerList(context.Background(), options)
+	err := s.cluster.Stop(ctx)
 	if err != nil {
-		panic(err)
+		return errors.Wrap(err, 1)
 	}
 
-	for _, container := range containers {
-		fmt.Printf("Stopping container %s...\n", container.ID)
-		if err := s.dockerClient.ContainerStop(context.Background(), container.ID, nil); err != nil {
-			panic(err)
-		}
-		fmt.Printf("Container %s stopped successfully.\n", container.ID)
-	}
+	fmt.Printf("Swarm nodes of cluster %s stopped successfully.\n", s.config.Cluster)
 
 	return nil
-
 }
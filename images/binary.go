@@ -0,0 +1,93 @@
+package images
+
+import (
+	"bufio"
+	"debug/elf"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// machines maps the architectures the Docker host reports to the machine of ELF binaries that
+// run on it.
+var machines = map[string]elf.Machine{
+	"x86_64":  elf.EM_X86_64,
+	"amd64":   elf.EM_X86_64,
+	"aarch64": elf.EM_AARCH64,
+	"arm64":   elf.EM_AARCH64,
+	"armv7l":  elf.EM_ARM,
+	"i386":    elf.EM_386,
+	"i686":    elf.EM_386,
+}
+
+// CheckBinary returns an error if the file at path isn't a Linux executable that runs in the
+// alpine based runtime image on a Docker host of the given architecture: it has to be built for
+// that architecture and either be statically linked or linked against musl. An architecture
+// unknown to swarmer isn't checked.
+func CheckBinary(path string, arch string) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		return errors.Errorf("%s is not a Linux executable: %s", path, err.Error())
+	}
+	defer f.Close()
+
+	if f.Type != elf.ET_EXEC && f.Type != elf.ET_DYN {
+		return errors.Errorf("%s is an ELF %s, not an executable", path, f.Type)
+	}
+
+	if machine, ok := machines[arch]; ok && f.Machine != machine {
+		return errors.Errorf("%s is built for %s but the Docker host runs %s", path, f.Machine, arch)
+	}
+
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			return errors.Errorf("Error reading the interpreter of %s: %s", path, err.Error())
+		}
+		if err := checkInterpreter(strings.TrimRight(string(data), "\x00")); err != nil {
+			return errors.Errorf("%s %s", path, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// checkInterpreter returns an error if a binary with the given dynamic linker can't run on alpine.
+func checkInterpreter(interp string) error {
+	if strings.Contains(interp, "ld-musl") {
+		return nil
+	}
+
+	return errors.Errorf("is dynamically linked with %s, which the alpine based image doesn't have; link it statically, e.g. with -ldflags '-linkmode external -extldflags -static', or against musl", interp)
+}
+
+// ParseVersion returns the version from the output of the version command of geth or swarm,
+// checking that it came from the program with the given name.
+func ParseVersion(output string, name string) (string, error) {
+	var program, version string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if program == "" && line != "" {
+			program = line
+		}
+		if strings.HasPrefix(line, "Version:") {
+			version = strings.TrimSpace(strings.TrimPrefix(line, "Version:"))
+			break
+		}
+	}
+
+	if !strings.EqualFold(program, name) {
+		return "", errors.Errorf("expected the version of %s, got %q", name, strings.TrimSpace(output))
+	}
+	if version == "" {
+		return "", errors.Errorf("%s printed no version: %q", name, strings.TrimSpace(output))
+	}
+
+	return version, nil
+}
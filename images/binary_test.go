@@ -0,0 +1,57 @@
+package images
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const swarmVersion = `Swarm
+Version: 0.3.6-stable
+Git Commit: 6b8b5b5d7b0b9d9c8f1d3c0e5e1f1a1b2c3d4e5f
+Go Version: go1.11.2
+OS: linux
+`
+
+func TestParseVersion(t *testing.T) {
+	version, err := ParseVersion(swarmVersion, "swarm")
+	if err != nil {
+		t.Fatalf("Error parsing version: %s", err.Error())
+	}
+	if version != "0.3.6-stable" {
+		t.Errorf("Expected version 0.3.6-stable, got %s", version)
+	}
+
+	if _, err := ParseVersion(swarmVersion, "geth"); err == nil {
+		t.Error("The version of swarm shouldn't pass for geth...")
+	}
+	if _, err := ParseVersion("Geth\n", "geth"); err == nil {
+		t.Error("Output without a version should have thrown an error...")
+	}
+	if _, err := ParseVersion("/bin/sh: /app/bin/geth: not found\n", "geth"); err == nil {
+		t.Error("An error message should have thrown an error...")
+	}
+}
+
+func TestCheckInterpreter(t *testing.T) {
+	if err := checkInterpreter("/lib/ld-musl-x86_64.so.1"); err != nil {
+		t.Errorf("A musl binary should run on alpine: %s", err.Error())
+	}
+	if err := checkInterpreter("/lib64/ld-linux-x86-64.so.2"); err == nil {
+		t.Error("A glibc binary should have thrown an error...")
+	}
+}
+
+func TestCheckBinary(t *testing.T) {
+	f, err := ioutil.TempFile("", "swarmer-binary")
+	if err != nil {
+		t.Fatalf("Error creating file: %s", err.Error())
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("#!/bin/sh\necho Swarm\n")
+	f.Close()
+
+	if err := CheckBinary(f.Name(), "x86_64"); err == nil {
+		t.Error("A shell script should have thrown an error...")
+	}
+}
@@ -0,0 +1,215 @@
+package images
+
+import (
+	"bufio"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// Repository is the Docker repository the Swarm images built by swarmer are tagged in.
+const Repository = "swarmer"
+
+// SourceExcludes are left out of the build context of a local source directory: version control
+// data and what earlier builds of go-ethereum left in its build directory.
+var SourceExcludes = []string{".git", ".hg", ".svn", "build/_workspace", "build/bin", "node_modules", "*.swp"}
+
+// maxSlug keeps the image name within the 255 characters Docker allows.
+const maxSlug = 200
+
+var (
+	fullHash  = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	shortHash = regexp.MustCompile(`^[0-9a-f]{4,39}$`)
+	nonAlnum  = regexp.MustCompile(`[^a-z0-9]+`)
+	scheme    = regexp.MustCompile(`^[a-z][a-z0-9+.-]*://`)
+)
+
+// IImages is the interface for managing the cache of Swarm images built by swarmer.
+type IImages interface {
+	Resolve(ctx context.Context, repo string, checkout string) (string, error)
+	List(ctx context.Context) ([]models.ImageInfo, error)
+	Remove(ctx context.Context, ids []string) ([]string, error)
+	Prune(ctx context.Context) ([]string, error)
+}
+
+// Images is the struct for this implementation of IImages.
+type Images struct {
+	dockerClient *client.Client
+}
+
+// GetImages returns a pointer to a new instance of this implementation of IImages.
+func GetImages(d *client.Client) *Images {
+	var s = Images{
+		dockerClient: d,
+	}
+
+	return &s
+}
+
+// Tag returns the tag of the Swarm image built from the given commit of repo. Images built from a
+// local source directory are tagged with its path and a hash of its contents instead.
+func Tag(repo string, commit string) string {
+	slug := strings.ToLower(strings.TrimSuffix(repo, ".git"))
+	slug = scheme.ReplaceAllString(slug, "")
+	slug = strings.Trim(nonAlnum.ReplaceAllString(slug, "-"), "-")
+	if len(slug) > maxSlug {
+		slug = strings.TrimRight(slug[:maxSlug], "-")
+	}
+
+	return Repository + "/" + slug + ":" + commit
+}
+
+// Labels returns the labels of the Swarm image built from the given commit of repo.
+func Labels(repo string, commit string) map[string]string {
+	return map[string]string{
+		orchestrator.DomainLabel: orchestrator.DomainValue,
+		orchestrator.RepoLabel:   repo,
+		orchestrator.CommitLabel: commit,
+	}
+}
+
+// Resolve returns the commit hash the branch, tag or commit checkout refers to in repo, asking
+// the repository with git ls-remote. An empty checkout is the default branch, and a full commit
+// hash is returned as it is.
+func (s *Images) Resolve(ctx context.Context, repo string, checkout string) (string, error) {
+	if fullHash.MatchString(checkout) {
+		return checkout, nil
+	}
+	if checkout == "" {
+		checkout = "HEAD"
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "ls-remote", repo, checkout, checkout+"^{}").Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			return "", errors.Errorf("git ls-remote %s failed: %s", repo, strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", err
+	}
+
+	return ParseLsRemote(string(out), checkout)
+}
+
+// ParseLsRemote returns the commit checkout refers to in the output of git ls-remote, preferring
+// the commit a tag points to over the tag itself, and branches over tags.
+func ParseLsRemote(out string, checkout string) (string, error) {
+	refs := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			refs[fields[1]] = fields[0]
+		}
+	}
+
+	for _, ref := range []string{
+		checkout,
+		"refs/heads/" + checkout,
+		"refs/tags/" + checkout + "^{}",
+		"refs/tags/" + checkout,
+	} {
+		if commit, ok := refs[ref]; ok {
+			return commit, nil
+		}
+	}
+
+	if shortHash.MatchString(checkout) {
+		return "", errors.Errorf("%s is not a branch or tag, give commits as the full 40 character hash", checkout)
+	}
+
+	return "", errors.Errorf("%s is not a branch or tag of the repository", checkout)
+}
+
+// List returns the Swarm images built by swarmer, newest first, and whether any container uses
+// them.
+func (s *Images) List(ctx context.Context) ([]models.ImageInfo, error) {
+	args := filters.NewArgs()
+	args.Add("label", orchestrator.DomainLabel+"="+orchestrator.DomainValue)
+	args.Add("label", orchestrator.RepoLabel)
+
+	images, err := s.dockerClient.ImageList(ctx, types.ImageListOptions{Filters: args})
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := s.dockerClient.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, container := range containers {
+		used[container.ImageID] = true
+	}
+
+	sort.Slice(images, func(i, j int) bool { return images[i].Created > images[j].Created })
+
+	var result []models.ImageInfo
+	for _, image := range images {
+		info := models.ImageInfo{
+			ID:      image.ID,
+			Repo:    image.Labels[orchestrator.RepoLabel],
+			Commit:  image.Labels[orchestrator.CommitLabel],
+			Size:    image.Size,
+			Created: time.Unix(image.Created, 0).UTC().Format(time.RFC3339),
+			InUse:   used[image.ID],
+		}
+		if len(image.RepoTags) > 0 && image.RepoTags[0] != "<none>:<none>" {
+			info.Tag = image.RepoTags[0]
+		}
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// Remove removes the Swarm images built by swarmer with the given IDs that no container uses,
+// and returns the IDs of the removed images.
+func (s *Images) Remove(ctx context.Context, ids []string) ([]string, error) {
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	return s.remove(ctx, func(image models.ImageInfo) bool { return wanted[image.ID] })
+}
+
+// Prune removes every Swarm image built by swarmer that no container uses, and returns the IDs
+// of the removed images.
+func (s *Images) Prune(ctx context.Context) ([]string, error) {
+	return s.remove(ctx, func(models.ImageInfo) bool { return true })
+}
+
+func (s *Images) remove(ctx context.Context, match func(models.ImageInfo) bool) ([]string, error) {
+	images, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, image := range images {
+		if image.InUse || !match(image) {
+			continue
+		}
+
+		// nothing uses the image, forcing only untags it from every name it has
+		_, err := s.dockerClient.ImageRemove(ctx, image.ID, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+		if client.IsErrNotFound(err) {
+			continue
+		} else if err != nil {
+			return removed, err
+		}
+		removed = append(removed, image.ID)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,65 @@
+package images
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+const lsRemote = `a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1	HEAD
+b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2	refs/heads/master
+c3c3c3c3c3c3c3c3c3c3c3c3c3c3c3c3c3c3c3c3	refs/tags/v1.8.0
+d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4	refs/tags/v1.8.0^{}
+`
+
+func TestTag(t *testing.T) {
+	commit := strings.Repeat("ab", 20)
+
+	for repo, expected := range map[string]string{
+		"https://github.com/ethereum/go-ethereum.git": "swarmer/github-com-ethereum-go-ethereum:" + commit,
+		"git@github.com:ethersphere/go-ethereum.git":  "swarmer/git-github-com-ethersphere-go-ethereum:" + commit,
+		"file:///src/Go-Ethereum/":                    "swarmer/src-go-ethereum:" + commit,
+	} {
+		if tag := Tag(repo, commit); tag != expected {
+			t.Errorf("Expected tag %s for %s, got %s", expected, repo, tag)
+		}
+	}
+
+	long := "https://example.com/" + strings.Repeat("a", 300)
+	if name := strings.Split(Tag(long, commit), ":")[0]; len(name) > 255 {
+		t.Errorf("Image name of %d characters is longer than Docker allows", len(name))
+	}
+}
+
+func TestParseLsRemote(t *testing.T) {
+	for checkout, expected := range map[string]string{
+		"HEAD":   "a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1",
+		"master": "b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2b2",
+		"v1.8.0": "d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4d4",
+	} {
+		commit, err := ParseLsRemote(lsRemote, checkout)
+		if err != nil {
+			t.Fatalf("Error resolving %s: %s", checkout, err.Error())
+		}
+		if commit != expected {
+			t.Errorf("Expected %s to resolve to %s, got %s", checkout, expected, commit)
+		}
+	}
+
+	if _, err := ParseLsRemote(lsRemote, "missing"); err == nil {
+		t.Error("An unknown branch should have thrown an error...")
+	}
+	if _, err := ParseLsRemote(lsRemote, "b2b2b2b"); err == nil || !strings.Contains(err.Error(), "full") {
+		t.Errorf("A short commit hash should ask for the full hash, got %v", err)
+	}
+}
+
+func TestResolveFullHash(t *testing.T) {
+	commit := strings.Repeat("0f", 20)
+
+	resolved, err := GetImages(nil).Resolve(context.Background(), "https://example.com/repo.git", commit)
+	if err != nil || resolved != commit {
+		t.Errorf("A full commit hash should be returned as it is, got %s, %v", resolved, err)
+	}
+}
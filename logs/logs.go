@@ -0,0 +1,167 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is how long lines are held back to be put in order with the lines of other nodes.
+const DefaultWindow = 200 * time.Millisecond
+
+// colors are the ANSI colors the prefixes of the nodes cycle through.
+var colors = []int{36, 33, 32, 35, 34, 31}
+
+// Source is the log of a single node, as lines prefixed with their timestamp, the way Docker
+// writes them with timestamps turned on.
+type Source struct {
+	Node   int
+	Reader io.Reader
+}
+
+// Line is a single line of the log of a node.
+type Line struct {
+	Node int
+	Time time.Time
+	Text string
+}
+
+// ParseLine splits the timestamp off a line of the log of a node. A line without a timestamp has
+// a zero time.
+func ParseLine(node int, s string) Line {
+	parts := strings.SplitN(s, " ", 2)
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Line{Node: node, Text: s}
+	}
+
+	line := Line{Node: node, Time: t}
+	if len(parts) == 2 {
+		line.Text = parts[1]
+	}
+
+	return line
+}
+
+// Prefix returns the node name every line of the node is prefixed with, padded to the width of
+// the prefix of the highest node index and colored if color is set.
+func Prefix(node int, maxNode int, color bool) string {
+	name := fmt.Sprintf("node %d", node)
+	width := len(fmt.Sprintf("node %d", maxNode))
+	prefix := fmt.Sprintf("%-*s |", width, name)
+
+	if !color {
+		return prefix
+	}
+
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", colors[node%len(colors)], prefix)
+}
+
+// Merge reads the lines of every source and writes them to w, prefixed with their node, in the
+// order of their timestamps. Lines are held back for window before they are written, so that lines
+// of different nodes read at about the same time are put in order. Once every source is read to
+// the end, the remaining lines are written. The first error reading a source or writing to w is
+// returned.
+func Merge(w io.Writer, sources []Source, window time.Duration, color bool) error {
+	maxNode := 0
+	for _, source := range sources {
+		if source.Node > maxNode {
+			maxNode = source.Node
+		}
+	}
+
+	// readErr is only read once every source is done
+	var mu sync.Mutex
+	var readErr error
+
+	lines := make(chan Line)
+	done := make(chan struct{})
+	defer close(done)
+
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source Source) {
+			defer wg.Done()
+
+			scanner := bufio.NewScanner(source.Reader)
+			scanner.Buffer(nil, 1024*1024)
+			for scanner.Scan() {
+				select {
+				case lines <- ParseLine(source.Node, scanner.Text()):
+				case <-done:
+					return
+				}
+			}
+
+			if err := scanner.Err(); err != nil {
+				mu.Lock()
+				if readErr == nil {
+					readErr = err
+				}
+				mu.Unlock()
+			}
+		}(source)
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	type held struct {
+		Line
+		received time.Time
+	}
+	var pending []held
+	var writeErr error
+
+	// flush writes the lines up to the newest timestamp of the lines held for at least window,
+	// or all of them
+	flush := func(all bool) {
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].Time.Before(pending[j].Time)
+		})
+
+		var cutoff time.Time
+		now := time.Now()
+		for _, p := range pending {
+			if now.Sub(p.received) >= window && p.Time.After(cutoff) {
+				cutoff = p.Time
+			}
+		}
+
+		n := 0
+		for ; n < len(pending) && (all || !pending[n].Time.After(cutoff)); n++ {
+			if writeErr == nil {
+				_, writeErr = fmt.Fprintf(w, "%s %s\n", Prefix(pending[n].Node, maxNode, color), pending[n].Text)
+			}
+		}
+		pending = pending[n:]
+	}
+
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				flush(true)
+				if readErr != nil {
+					return readErr
+				}
+				return writeErr
+			}
+			pending = append(pending, held{line, time.Now()})
+		case <-ticker.C:
+			flush(false)
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+	}
+}
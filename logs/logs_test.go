@@ -0,0 +1,57 @@
+package logs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	line := ParseLine(1, "2018-10-18T04:23:02.123456789Z INFO [10-18|04:23:02] Starting peer-to-peer node")
+
+	expected := time.Date(2018, 10, 18, 4, 23, 2, 123456789, time.UTC)
+	if !line.Time.Equal(expected) {
+		t.Errorf("Expected the time %s, got %s", expected, line.Time)
+	}
+	if line.Node != 1 || line.Text != "INFO [10-18|04:23:02] Starting peer-to-peer node" {
+		t.Errorf("Expected the text of node 1 without the timestamp, got %+v", line)
+	}
+
+	line = ParseLine(0, "no timestamp")
+	if !line.Time.IsZero() || line.Text != "no timestamp" {
+		t.Errorf("A line without a timestamp should be kept as it is, got %+v", line)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	if prefix := Prefix(2, 10, false); prefix != "node 2  |" {
+		t.Errorf("Expected the prefix to be padded to the widest node, got %q", prefix)
+	}
+	if prefix := Prefix(1, 1, true); prefix != "\x1b[33mnode 1 |\x1b[0m" {
+		t.Errorf("Expected a colored prefix, got %q", prefix)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	sources := []Source{
+		{Node: 0, Reader: strings.NewReader(
+			"2018-10-18T04:23:01Z first\n" +
+				"2018-10-18T04:23:03Z third\n",
+		)},
+		{Node: 1, Reader: strings.NewReader(
+			"2018-10-18T04:23:02Z second\n" +
+				"2018-10-18T04:23:04Z fourth\n",
+		)},
+	}
+
+	var out bytes.Buffer
+	if err := Merge(&out, sources, time.Second, false); err != nil {
+		t.Fatalf("Error merging logs: %s", err.Error())
+	}
+
+	expected := "node 0 | first\nnode 1 | second\nnode 0 | third\nnode 1 | fourth\n"
+	if out.String() != expected {
+		t.Errorf("Expected the lines in the order of their timestamps:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
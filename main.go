@@ -6,6 +6,13 @@ import (
 	"time"
 
 	"github.com/MainframeHQ/swarmer/admin"
+	"github.com/MainframeHQ/swarmer/chaos"
+	"github.com/MainframeHQ/swarmer/cluster"
+	"github.com/MainframeHQ/swarmer/images"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/MainframeHQ/swarmer/readiness"
+	"github.com/MainframeHQ/swarmer/state"
 	"github.com/MainframeHQ/swarmer/util"
 	"github.com/docker/docker/client"
 
@@ -17,10 +24,6 @@ import (
 	models "github.com/MainframeHQ/swarmer/models"
 )
 
-// DATAPATH is a best effort at finding the src directory of this project in order
-// to make use of the Dockerfile and docker-compose.yml files etc..
-var DATAPATH = os.Getenv("GOPATH") + "/src/github.com/MainframeHQ/swarmer/"
-
 // APPNAME is aptly named.
 const APPNAME = "swarmer"
 
@@ -38,28 +41,46 @@ func init() {
 	} else if runtime.GOOS == "linux" {
 		log.Debug("Linux detected")
 	} else {
-		log.Fatal(APPNAME+" does not support the %s operating system at this time.", runtime.GOOS)
+		log.Fatalf(APPNAME+" does not support the %s operating system at this time.", runtime.GOOS)
 	}
 
 }
 
 func main() {
-	path := DATAPATH + "docker"
 	config := models.Config{}
-	config.Path = path
 
 	var start *cmd.StartCommand
 	var stop *cmd.StopCommand
 	var status *cmd.StatusCommand
+	var list *cmd.ListCommand
+	var down *cmd.DownCommand
+	var scale *cmd.ScaleCommand
+	var run *cmd.RunCommand
+	var chaosCommand *cmd.ChaosCommand
+	var netemCommand *cmd.NetemCommand
+	var partitionCommand *cmd.PartitionCommand
+	var configCommand *cmd.ConfigCommand
+	var logsCommand *cmd.LogsCommand
+	var imagesCommand *cmd.ImagesCommand
+	var configSources map[string]string
 
-	dockerClient, err := client.NewClientWithOpts(client.WithVersion("1.38"))
+	dockerClient, err := client.NewClientWithOpts(client.WithVersion(cluster.DockerAPIVersion))
 	if err != nil {
-		panic("Must have Docker compatible with API 1.38: " + err.Error())
+		panic("Must have Docker compatible with API " + cluster.DockerAPIVersion + ": " + err.Error())
 	}
 
+	orch := orchestrator.GetOrchestrator(dockerClient)
+	faults := chaos.GetChaos(dockerClient)
 	adminClient := admin.GetClient()
-	lookup := util.GetLookup()
+	checker := readiness.GetChecker(adminClient)
 	parser := util.GetConfigParser()
+	store := state.GetStore(state.DefaultDir)
+	imageCache := images.GetImages(dockerClient)
+
+	// getCluster is called by the command actions, once the config has been loaded
+	getCluster := func() *cluster.Cluster {
+		return cluster.GetCluster(config, dockerClient, orch, checker, adminClient, store, imageCache, configSources)
+	}
 
 	app := cli.NewApp()
 	app.Name = APPNAME
@@ -75,53 +96,373 @@ func main() {
 	app.Copyright = "(c) 2018 Mainframe"
 	app.EnableBashCompletion = true
 
+	// withConfig loads the config file, environment and flags into config before running the
+	// action. It is done by the actions rather than app.Before, so that an invalid config is
+	// reported without the help text and only by the commands that use it. list and images act on
+	// every cluster and image of the Docker host, so they only take the global flags.
+	withConfig := func(action cli.ActionFunc) cli.ActionFunc {
+		return func(c *cli.Context) error {
+			merged, sources, err := cmd.LoadConfig(c, &config, parser)
+			if err != nil {
+				return err
+			}
+
+			config = merged
+			configSources = sources
+
+			return action(c)
+		}
+	}
+
+	// withLenientConfig is withConfig for the commands that stop or remove a cluster, which go on
+	// without the config file if it is invalid, so that a broken file can't keep a cluster running
+	withLenientConfig := func(action cli.ActionFunc) cli.ActionFunc {
+		return func(c *cli.Context) error {
+			merged, sources, err := cmd.LoadConfig(c, &config, parser)
+			if err != nil {
+				log.Warnf("Ignoring the config file: %s", err.Error())
+			}
+
+			config = merged
+			configSources = sources
+
+			return action(c)
+		}
+	}
+
+	selectionFlags := []cli.Flag{
+		cli.IntSliceFlag{
+			Name:  "node, i",
+			Usage: "index of a node to target, may be repeated",
+		},
+		cli.IntFlag{
+			Name:  "random, R",
+			Usage: "number of nodes to target at random, besides those given with --node",
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "seed for the random selection, to repeat an earlier run, 0 picking one from the current time",
+		},
+	}
+
+	injectCommand := func(name string, usage string) cli.Command {
+		return cli.Command{
+			Name:  name,
+			Usage: usage,
+			Flags: selectionFlags,
+			Action: withConfig(func(c *cli.Context) error {
+				chaosCommand = cmd.GetChaosCommand(config, faults)
+				err := chaosCommand.Inject(c)
+
+				return err
+			}),
+		}
+	}
+
 	app.Commands = []cli.Command{
 		{
 			Name:    "start",
 			Aliases: []string{"s"},
 			Usage:   "Start the Swarm cluster",
-			Action: func(c *cli.Context) error {
-				start = cmd.GetStartCommand(config, dockerClient, adminClient, lookup, parser)
+			Action: withConfig(func(c *cli.Context) error {
+				start = cmd.GetStartCommand(config, dockerClient, getCluster())
 				err := start.Start(c)
 
 				return err
-			},
+			}),
 		},
 		{
 			Name:    "stop",
 			Aliases: []string{"t"},
 			Usage:   "Stop the Swarm cluster",
-			Action: func(c *cli.Context) error {
-				stop = cmd.GetStopCommand(config, dockerClient)
+			Action: withLenientConfig(func(c *cli.Context) error {
+				stop = cmd.GetStopCommand(config, getCluster())
 				err := stop.Stop(c)
 
 				return err
+			}),
+		},
+		{
+			Name:    "down",
+			Aliases: []string{"destroy"},
+			Usage:   "Remove the containers, volumes and network of the Swarm cluster, and the Swarm images no other cluster uses",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "keep-images",
+					Usage: "keep the Swarm images the cluster was started from",
+				},
+				cli.BoolFlag{
+					Name:  "keep-volumes",
+					Usage: "keep the volumes mounted into the Swarm containers",
+				},
+			},
+			Action: withLenientConfig(func(c *cli.Context) error {
+				down = cmd.GetDownCommand(config, getCluster())
+				err := down.Down(c)
+
+				return err
+			}),
+		},
+		{
+			Name:      "scale",
+			Usage:     "Start or stop nodes of the running Swarm cluster until it has the given number of nodes",
+			ArgsUsage: "<number of nodes>",
+			Action: withConfig(func(c *cli.Context) error {
+				scale = cmd.GetScaleCommand(config, getCluster())
+				err := scale.Scale(c)
+
+				return err
+			}),
+		},
+		{
+			Name:      "run",
+			Usage:     "Start the Swarm cluster, run a command with the node details in its environment, then tear the cluster down",
+			ArgsUsage: "-- <command> [arguments...]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "keep-on-failure",
+					Usage: "keep the cluster running if it fails to start or the command fails, for debugging",
+				},
 			},
+			Action: withConfig(func(c *cli.Context) error {
+				run = cmd.GetRunCommand(config, getCluster())
+				err := run.Run(c)
+
+				return err
+			}),
+		},
+		{
+			Name:  "chaos",
+			Usage: "Inject faults into the Swarm nodes, printing every action as a line of JSON",
+			Subcommands: []cli.Command{
+				injectCommand(chaos.Kill, "Kill the nodes"),
+				injectCommand(chaos.Pause, "Pause the nodes"),
+				injectCommand(chaos.Unpause, "Unpause the nodes"),
+				injectCommand(chaos.Restart, "Restart the nodes, starting them if they were killed"),
+				injectCommand(chaos.Disconnect, "Disconnect the nodes from the cluster network"),
+				injectCommand(chaos.Reconnect, "Reconnect the nodes to the cluster network"),
+				{
+					Name:      "run",
+					Usage:     "Apply the faults of a YAML plan over time",
+					ArgsUsage: "<plan.yml>",
+					Action: withConfig(func(c *cli.Context) error {
+						chaosCommand = cmd.GetChaosCommand(config, faults)
+						err := chaosCommand.Run(c)
+
+						return err
+					}),
+				},
+			},
+		},
+		{
+			Name:  "netem",
+			Usage: "Impair the network traffic of the Swarm nodes with delay, loss and rate limits",
+			Subcommands: []cli.Command{
+				{
+					Name:  "apply",
+					Usage: "Shape the traffic of every node according to the netem rules of the config",
+					Action: withConfig(func(c *cli.Context) error {
+						netemCommand = cmd.GetNetemCommand(config, getCluster())
+						err := netemCommand.Apply(c)
+
+						return err
+					}),
+				},
+				{
+					Name:  "set",
+					Usage: "Replace the impairment of the nodes with the one given by the flags",
+					Flags: []cli.Flag{
+						cli.IntSliceFlag{
+							Name:  "node, i",
+							Usage: "index of a node to shape, may be repeated (default: every node)",
+						},
+						cli.IntSliceFlag{
+							Name:  "peer, p",
+							Usage: "only impair traffic sent to this node, may be repeated (default: all traffic)",
+						},
+						cli.DurationFlag{
+							Name:  "delay",
+							Usage: "delay every packet by this long",
+						},
+						cli.DurationFlag{
+							Name:  "jitter",
+							Usage: "vary the delay by up to this long",
+						},
+						cli.Float64Flag{
+							Name:  "loss",
+							Usage: "percentage of packets to drop",
+						},
+						cli.StringFlag{
+							Name:  "rate",
+							Usage: "bandwidth limit, e.g. 1mbit or 500kbit",
+						},
+					},
+					Action: withConfig(func(c *cli.Context) error {
+						netemCommand = cmd.GetNetemCommand(config, getCluster())
+						err := netemCommand.Set(c)
+
+						return err
+					}),
+				},
+				{
+					Name:  "clear",
+					Usage: "Remove the impairment of the nodes",
+					Flags: []cli.Flag{
+						cli.IntSliceFlag{
+							Name:  "node, i",
+							Usage: "index of a node to shape, may be repeated (default: every node)",
+						},
+					},
+					Action: withConfig(func(c *cli.Context) error {
+						netemCommand = cmd.GetNetemCommand(config, getCluster())
+						err := netemCommand.Clear(c)
+
+						return err
+					}),
+				},
+			},
+		},
+		{
+			Name:  "partition",
+			Usage: "Split the Swarm nodes into groups that can't reach each other",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "groups, G",
+					Usage: "node indexes of each group, groups separated by '/' and optionally named, e.g. 0,1,2/3,4 or left=0,1/right=2",
+				},
+			},
+			Action: withConfig(func(c *cli.Context) error {
+				partitionCommand = cmd.GetPartitionCommand(config, getCluster())
+				err := partitionCommand.Partition(c)
+
+				return err
+			}),
+		},
+		{
+			Name:  "heal",
+			Usage: "Remove the partition of the Swarm nodes",
+			Action: withConfig(func(c *cli.Context) error {
+				partitionCommand = cmd.GetPartitionCommand(config, getCluster())
+				err := partitionCommand.Heal(c)
+
+				return err
+			}),
 		},
 		{
 			Name:    "status",
 			Aliases: []string{"a"},
 			Usage:   "Get a list of running nodes",
-			Action: func(c *cli.Context) error {
-				status = cmd.GetStatusCommand(config, dockerClient, adminClient)
+			Action: withConfig(func(c *cli.Context) error {
+				status = cmd.GetStatusCommand(config, getCluster())
 				err := status.Status(c)
 
+				return err
+			}),
+		},
+		{
+			Name:      "logs",
+			Usage:     "Show the logs of the Swarm nodes, merged in the order they were written",
+			ArgsUsage: "[node index...]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "follow, f",
+					Usage: "keep showing new lines until interrupted",
+				},
+				cli.StringFlag{
+					Name:  "since",
+					Usage: "only show lines since a timestamp, e.g. 2018-10-18T04:23:00Z, or a duration ago, e.g. 10m",
+				},
+				cli.StringFlag{
+					Name:  "tail",
+					Value: "all",
+					Usage: "number of lines to show from the end of the log of each node",
+				},
+				cli.BoolFlag{
+					Name:  "no-color",
+					Usage: "don't color the node prefixes, which are only colored on a terminal",
+				},
+			},
+			Action: withConfig(func(c *cli.Context) error {
+				logsCommand = cmd.GetLogsCommand(config, dockerClient)
+				err := logsCommand.Logs(c)
+
+				return err
+			}),
+		},
+		{
+			Name:  "config",
+			Usage: "Inspect the effective configuration",
+			Subcommands: []cli.Command{
+				{
+					Name:  "show",
+					Usage: "Show every config value and whether it came from a default, the config file, the environment or a flag",
+					Action: withConfig(func(c *cli.Context) error {
+						configCommand = cmd.GetConfigCommand(config, configSources)
+						err := configCommand.Show(c)
+
+						return err
+					}),
+				},
+			},
+		},
+		{
+			Name:  "images",
+			Usage: "Manage the Swarm images swarmer builds for every repo and commit",
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the Swarm images and whether any container uses them",
+					Action: func(c *cli.Context) error {
+						imagesCommand = cmd.GetImagesCommand(config, imageCache)
+						err := imagesCommand.List(c)
+
+						return err
+					},
+				},
+				{
+					Name:  "prune",
+					Usage: "Remove the Swarm images no container uses",
+					Action: func(c *cli.Context) error {
+						imagesCommand = cmd.GetImagesCommand(config, imageCache)
+						err := imagesCommand.Prune(c)
+
+						return err
+					},
+				},
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"ls"},
+			Usage:   "List the Swarm clusters on this Docker host",
+			Action: func(c *cli.Context) error {
+				list = cmd.GetListCommand(config, dockerClient)
+				err := list.List(c)
+
 				return err
 			},
 		},
 	}
 	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:        "cluster, N",
+			Value:       orchestrator.DefaultCluster,
+			Usage:       "name of the cluster to act on, so several can run side by side",
+			EnvVar:      "DEVCLUSTER_CLUSTER",
+			Destination: &config.Cluster,
+		},
 		cli.IntFlag{
 			Name:        "nodes, n",
 			Value:       1,
 			Usage:       "how many swarm nodes to start",
 			EnvVar:      "DEVCLUSTER_NODES",
-			Destination: &config.Nodes,
+			Destination: &config.Nodes.Count,
 		},
 		cli.StringFlag{
 			Name:        "config, C",
 			Value:       "",
-			Usage:       "load a YAML or TOML config file rather than supplying args and flags",
+			Usage:       "load a YAML, TOML or JSON config file rather than supplying args and flags",
 			EnvVar:      "DEVCLUSTER_CONFIG",
 			Destination: &config.Config,
 		},
@@ -146,6 +487,27 @@ func main() {
 			EnvVar:      "DEVCLUSTER_CHECKOUT",
 			Destination: &config.Checkout,
 		},
+		cli.StringFlag{
+			Name:        "image",
+			Value:       "",
+			Usage:       "start the nodes from this published Swarm image rather than building one",
+			EnvVar:      "DEVCLUSTER_IMAGE",
+			Destination: &config.Image,
+		},
+		cli.StringFlag{
+			Name:        "swarm-binary",
+			Value:       "",
+			Usage:       "run this swarm binary built on the host rather than building one, needs --geth-binary",
+			EnvVar:      "DEVCLUSTER_SWARM_BINARY",
+			Destination: &config.SwarmBinary,
+		},
+		cli.StringFlag{
+			Name:        "geth-binary",
+			Value:       "",
+			Usage:       "run this geth binary built on the host rather than building one, needs --swarm-binary",
+			EnvVar:      "DEVCLUSTER_GETH_BINARY",
+			Destination: &config.GethBinary,
+		},
 		cli.StringFlag{
 			Name:        "ens-api, e",
 			Value:       "",
@@ -159,6 +521,13 @@ func main() {
 			EnvVar:      "DEVCLUSTER_GETH",
 			Destination: &config.Geth,
 		},
+		cli.StringFlag{
+			Name:        "assets",
+			Value:       "",
+			Usage:       "build the Swarm images from the Dockerfiles and start.sh in this directory rather than the ones built into swarmer",
+			EnvVar:      "DEVCLUSTER_ASSETS",
+			Destination: &config.Path,
+		},
 		cli.StringFlag{
 			Name:        "docker_log, b",
 			Value:       "docker_log",
@@ -169,16 +538,14 @@ func main() {
 		cli.StringFlag{
 			Name:        "swarm_log, s",
 			Value:       "swarm_log",
-			Usage:       "local logfile for Swarm logs",
+			Usage:       "local logfile for Swarm logs, one per node with the cluster and node index added to the name",
 			EnvVar:      "DEVCLUSTER_SWARM_LOG",
 			Destination: &config.SwarmLog,
 		},
-		cli.StringFlag{
-			Name:        "add, a",
-			Value:       "",
-			Usage:       "add directory to the swarmer containers",
-			EnvVar:      "DEVCLUSTER_ADD",
-			Destination: &config.Add,
+		cli.StringSliceFlag{
+			Name:   "add, a",
+			Usage:  "mount a directory in the swarmer containers, given as source[:target][:ro|rw] (repeatable)",
+			EnvVar: "DEVCLUSTER_ADD",
 		},
 		cli.BoolFlag{
 			Name:        "follow, f",
@@ -186,15 +553,70 @@ func main() {
 			EnvVar:      "DEVCLUSTER_FOLLOW",
 			Destination: &config.Follow,
 		},
+		cli.BoolFlag{
+			Name:        "recreate",
+			Usage:       "rebuild or pull the images again and recreate the nodes, even if matching nodes are running",
+			EnvVar:      "DEVCLUSTER_RECREATE",
+			Destination: &config.Recreate,
+		},
+		cli.StringFlag{
+			Name:        "output, o",
+			Value:       output.JSON,
+			Usage:       "format of the node details: json, yaml, dotenv, export, template=<go template> or template-file=<path>",
+			EnvVar:      "DEVCLUSTER_OUTPUT",
+			Destination: &config.Output,
+		},
+		cli.DurationFlag{
+			Name:        "ready-timeout",
+			Value:       readiness.DefaultTimeout,
+			Usage:       "how long to wait for every node's admin RPC, gateway and websocket to answer",
+			EnvVar:      "DEVCLUSTER_READY_TIMEOUT",
+			Destination: &config.ReadyTimeout,
+		},
+		cli.DurationFlag{
+			Name:        "ready-backoff",
+			Value:       readiness.DefaultBackoff,
+			Usage:       "initial delay between readiness probes of a node, doubled after each failure",
+			EnvVar:      "DEVCLUSTER_READY_BACKOFF",
+			Destination: &config.ReadyBackoff,
+		},
+		cli.StringFlag{
+			Name:        "topology, T",
+			Value:       "ring",
+			Usage:       "how to peer the nodes: ring, mesh, star, line, random or explicit (YAML only)",
+			EnvVar:      "DEVCLUSTER_TOPOLOGY",
+			Destination: &config.Topology.Type,
+		},
+		cli.IntFlag{
+			Name:        "topology-hub",
+			Value:       0,
+			Usage:       "index of the node every other node peers with in a star topology",
+			EnvVar:      "DEVCLUSTER_TOPOLOGY_HUB",
+			Destination: &config.Topology.Hub,
+		},
+		cli.IntFlag{
+			Name:        "topology-degree",
+			Value:       2,
+			Usage:       "number of peers of each node in a random topology",
+			EnvVar:      "DEVCLUSTER_TOPOLOGY_DEGREE",
+			Destination: &config.Topology.Degree,
+		},
+		cli.Int64Flag{
+			Name:        "topology-seed",
+			Value:       0,
+			Usage:       "seed for a reproducible random topology",
+			EnvVar:      "DEVCLUSTER_TOPOLOGY_SEED",
+			Destination: &config.Topology.Seed,
+		},
 	}
 
-	app.Action = func(c *cli.Context) error {
+	app.Action = withConfig(func(c *cli.Context) error {
 		// this uses the start command as default if no command given
-		start = cmd.GetStartCommand(config, dockerClient, adminClient, lookup, parser)
+		start = cmd.GetStartCommand(config, dockerClient, getCluster())
 		err := start.Start(c)
 
 		return errors.Wrap(err, 1)
-	}
+	})
 
 	err = app.Run(os.Args)
 	if err != nil {
@@ -1,11 +1,15 @@
 package models
 
+import "time"
+
 // Config defines the values needed by the application at runtime.
 type Config struct {
+	Cluster   string `json:"cluster" yaml:"cluster"`
 	LocalSrc  string `json:"local-src" yaml:"local-src"`
 	Repo      string `json:"repo" yaml:"repo"`
 	Checkout  string `json:"checkout" yaml:"checkout"`
-	Nodes     int    `json:"nodes" yaml:"nodes"`
+	Image     string `json:"image" yaml:"image"`
+	Nodes     Nodes  `json:"nodes" yaml:"nodes"`
 	ENS       string `json:"ens-api" yaml:"ens-api"`
 	LogLevel  string `json:"loglevel" yaml:"loglevel"`
 	Geth      bool   `json:"geth" yaml:"geth"`
@@ -13,6 +17,26 @@ type Config struct {
 	Path      string `json:"path" yaml:"path"`
 	DockerLog string `json:"docker_log" yaml:"docker_log"`
 	SwarmLog  string `json:"swarm_log" yaml:"swarm_log"`
-	Add       string `json:"add" yaml:"add"`
+	Add       Mounts `json:"add" yaml:"add"`
 	Follow    bool   `json:"follow" yaml:"follow"`
+	Output    string `json:"output" yaml:"output"`
+	Recreate  bool   `json:"recreate" yaml:"recreate"`
+
+	// SwarmBinary and GethBinary are executables built on the host to run instead of building
+	// go-ethereum.
+	SwarmBinary string `json:"swarm-binary" yaml:"swarm-binary"`
+	GethBinary  string `json:"geth-binary" yaml:"geth-binary"`
+
+	ReadyTimeout time.Duration `json:"ready_timeout" yaml:"ready_timeout"`
+	ReadyBackoff time.Duration `json:"ready_backoff" yaml:"ready_backoff"`
+
+	Topology Topology `json:"topology" yaml:"topology"`
+	Netem    []Netem  `json:"netem" yaml:"netem"`
+}
+
+// ConfigValue is a single effective config value and where it came from.
+type ConfigValue struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Source string      `json:"source" yaml:"source"`
 }
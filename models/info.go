@@ -38,6 +38,8 @@ type NodeInfo struct {
 	ContainerID    string   `json:"container_id" yaml:"container_id"`
 	ContainerNames []string `json:"container_names" yaml:"container_names"`
 	IPAddress      string   `json:"ip" yaml:"ip"`
+	// Partition is the group the node is in while the cluster is partitioned.
+	Partition string `json:"partition,omitempty" yaml:"partition,omitempty"`
 }
 
 // Ports maps go-ethereum ports section of NodeInfo.
@@ -45,3 +47,56 @@ type Ports struct {
 	Discovery int
 	Listener  int
 }
+
+// ClusterInfo summarises a cluster found on the Docker host.
+type ClusterInfo struct {
+	Name    string `json:"name" yaml:"name"`
+	Network string `json:"network" yaml:"network"`
+	Nodes   int    `json:"nodes" yaml:"nodes"`
+	Running int    `json:"running" yaml:"running"`
+}
+
+// TeardownInfo lists the Docker resources removed when a cluster is torn down.
+type TeardownInfo struct {
+	Cluster    string   `json:"cluster" yaml:"cluster"`
+	Containers []string `json:"containers" yaml:"containers"`
+	Volumes    []string `json:"volumes" yaml:"volumes"`
+	Networks   []string `json:"networks" yaml:"networks"`
+	Images     []string `json:"images" yaml:"images"`
+}
+
+// ChaosEvent records a single fault applied to, or reverted on, a node.
+type ChaosEvent struct {
+	Time      string `json:"time" yaml:"time"`
+	Cluster   string `json:"cluster" yaml:"cluster"`
+	Action    string `json:"action" yaml:"action"`
+	Node      int    `json:"node" yaml:"node"`
+	Container string `json:"container" yaml:"container"`
+	Seed      int64  `json:"seed,omitempty" yaml:"seed,omitempty"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NetemStatus lists the tc commands that shaped the traffic of a node, none if it was cleared.
+type NetemStatus struct {
+	Node      int      `json:"node" yaml:"node"`
+	Container string   `json:"container" yaml:"container"`
+	Commands  []string `json:"commands" yaml:"commands"`
+}
+
+// PartitionStatus records the partition group of a node, none if it was healed.
+type PartitionStatus struct {
+	Node      int    `json:"node" yaml:"node"`
+	Container string `json:"container" yaml:"container"`
+	Group     string `json:"group,omitempty" yaml:"group,omitempty"`
+}
+
+// ImageInfo describes a Swarm image built by swarmer.
+type ImageInfo struct {
+	Tag     string `json:"tag" yaml:"tag"`
+	ID      string `json:"id" yaml:"id"`
+	Repo    string `json:"repo" yaml:"repo"`
+	Commit  string `json:"commit" yaml:"commit"`
+	Size    int64  `json:"size" yaml:"size"`
+	Created string `json:"created" yaml:"created"`
+	InUse   bool   `json:"in_use" yaml:"in_use"`
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMountTarget is where a directory added without a target is mounted in the nodes.
+const DefaultMountTarget = "/swarmer"
+
+// Mount is a directory on the host that is bind-mounted into every node.
+type Mount struct {
+	Source string `json:"source" yaml:"source"`
+	// Target is the absolute path of the mount in the container. Empty means /swarmer.
+	Target string `json:"target" yaml:"target"`
+	// Mode is either ro or rw. Empty means ro.
+	Mode string `json:"mode" yaml:"mode"`
+}
+
+// ParseMount parses a mount given as `source[:target][:ro|rw]`, e.g. `./data:/data:rw`.
+func ParseMount(spec string) (Mount, error) {
+	parts := strings.Split(spec, ":")
+
+	var m Mount
+	if last := parts[len(parts)-1]; len(parts) > 1 && (last == "ro" || last == "rw") {
+		m.Mode = last
+		parts = parts[:len(parts)-1]
+	}
+
+	switch len(parts) {
+	case 1:
+		m.Source = parts[0]
+	case 2:
+		m.Source, m.Target = parts[0], parts[1]
+	default:
+		return Mount{}, fmt.Errorf("invalid directory to add %q, expected source[:target][:ro|rw]", spec)
+	}
+
+	if m.Source == "" {
+		return Mount{}, fmt.Errorf("invalid directory to add %q, the source is empty", spec)
+	}
+
+	return m, nil
+}
+
+// String returns the mount in the form ParseMount takes.
+func (m Mount) String() string {
+	s := m.Source
+	if m.Target != "" {
+		s += ":" + m.Target
+	}
+	if m.Mode != "" {
+		s += ":" + m.Mode
+	}
+
+	return s
+}
+
+// UnmarshalYAML allows a mount to be given either in the form ParseMount takes, e.g.
+// `- ./data:/data:rw`, or as a mapping of source, target and mode.
+func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var spec string
+	if err := unmarshal(&spec); err == nil {
+		*m, err = ParseMount(spec)
+		return err
+	}
+
+	type plain Mount
+	return unmarshal((*plain)(m))
+}
+
+// Mounts are the directories added to the nodes.
+type Mounts []Mount
+
+// ParseMounts parses mounts given in the form ParseMount takes, e.g. by repeating --add.
+func ParseMounts(specs []string) (Mounts, error) {
+	var m Mounts
+	for _, spec := range specs {
+		mount, err := ParseMount(spec)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, mount)
+	}
+
+	return m, nil
+}
+
+// String returns the mounts in the form ParseMount takes, separated by commas.
+func (m Mounts) String() string {
+	specs := make([]string, len(m))
+	for i, mount := range m {
+		specs[i] = mount.String()
+	}
+
+	return strings.Join(specs, ",")
+}
+
+// UnmarshalYAML allows a single mount, or mounts separated by commas, to be given in place of a
+// list, e.g. `add: ./data`.
+func (m *Mounts) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var spec string
+	if err := unmarshal(&spec); err == nil {
+		var specs []string
+		for _, s := range strings.Split(spec, ",") {
+			specs = append(specs, strings.TrimSpace(s))
+		}
+		*m, err = ParseMounts(specs)
+		return err
+	}
+
+	var list []Mount
+	err := unmarshal(&list)
+	*m = list
+	return err
+}
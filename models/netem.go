@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Netem impairs the traffic sent by nodes, either all of it or only the traffic to some peers.
+type Netem struct {
+	// Nodes are the indexes of the nodes whose outgoing traffic is impaired. Empty means all.
+	Nodes []int `json:"nodes" yaml:"nodes"`
+	// Peers limits the impairment to traffic sent to these nodes. Empty means all traffic.
+	Peers []int `json:"peers" yaml:"peers"`
+
+	Delay  time.Duration `json:"delay" yaml:"delay"`
+	Jitter time.Duration `json:"jitter" yaml:"jitter"`
+	// Loss is the percentage of packets dropped.
+	Loss float64 `json:"loss" yaml:"loss"`
+	// Rate limits the bandwidth, in tc units such as 1mbit or 500kbit.
+	Rate string `json:"rate" yaml:"rate"`
+}
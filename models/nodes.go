@@ -0,0 +1,68 @@
+package models
+
+// Nodes is the number of Swarm nodes to start, along with the settings of any of them that
+// differ from the rest of the cluster.
+type Nodes struct {
+	// Count is the number of nodes to start.
+	Count int `json:"count" yaml:"count"`
+	// Overrides holds the settings of the first len(Overrides) nodes, by node index.
+	Overrides []NodeConfig `json:"overrides" yaml:"overrides"`
+}
+
+// NodeConfig overrides the cluster wide settings for a single node. Empty values use the
+// cluster's.
+type NodeConfig struct {
+	Checkout string `json:"checkout" yaml:"checkout"`
+	ENS      string `json:"ens-api" yaml:"ens-api"`
+	Geth     *bool  `json:"geth" yaml:"geth"`
+	// Verbosity is the Swarm log level, from 0 (silent) to 5 (detail). Nil means 5.
+	Verbosity *int `json:"verbosity" yaml:"verbosity"`
+	// SwarmFlags are passed to the swarm command after the flags swarmer sets.
+	SwarmFlags []string `json:"swarm-flags" yaml:"swarm-flags"`
+	// CPUs limits the CPU time of the node's container, e.g. 0.5 for half a core.
+	CPUs float64 `json:"cpus" yaml:"cpus"`
+	// Memory limits the memory of the node's container, e.g. 512m or 2g.
+	Memory string `json:"memory" yaml:"memory"`
+}
+
+// UnmarshalYAML allows nodes to be given either as a plain count, e.g. `nodes: 3`, as a list
+// with the overrides of every node, or as a mapping of count and overrides.
+func (n *Nodes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var count int
+	if err := unmarshal(&count); err == nil {
+		*n = Nodes{Count: count}
+		return nil
+	}
+
+	var list []interface{}
+	if err := unmarshal(&list); err == nil {
+		var overrides []NodeConfig
+		err = unmarshal(&overrides)
+		*n = Nodes{Count: len(overrides), Overrides: overrides}
+		return err
+	}
+
+	type plain Nodes
+	return unmarshal((*plain)(n))
+}
+
+// Node returns the settings of node i, with its overrides applied to the cluster settings.
+func (c Config) Node(i int) NodeConfig {
+	node := NodeConfig{}
+	if i < len(c.Nodes.Overrides) {
+		node = c.Nodes.Overrides[i]
+	}
+
+	if node.Checkout == "" {
+		node.Checkout = c.Checkout
+	}
+	if node.ENS == "" {
+		node.ENS = c.ENS
+	}
+	if node.Geth == nil {
+		geth := c.Geth
+		node.Geth = &geth
+	}
+
+	return node
+}
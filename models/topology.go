@@ -0,0 +1,28 @@
+package models
+
+// Topology describes how the Swarm nodes are peered with each other once they are running.
+type Topology struct {
+	// Type is one of ring, mesh, star, line, random or explicit. Empty means ring.
+	Type string `json:"type" yaml:"type"`
+	// Hub is the index of the node every other node peers with in a star topology.
+	Hub int `json:"hub" yaml:"hub"`
+	// Degree is the number of peers of each node in a random k-regular topology.
+	Degree int `json:"degree" yaml:"degree"`
+	// Seed makes a random topology reproducible.
+	Seed int64 `json:"seed" yaml:"seed"`
+	// Peers is the adjacency list of an explicit topology, keyed by node index.
+	Peers map[int][]int `json:"peers" yaml:"peers"`
+}
+
+// UnmarshalYAML allows a topology to be given either as a plain type name, e.g. `topology: mesh`,
+// or as a mapping with the options for that type.
+func (t *Topology) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*t = Topology{Type: name}
+		return nil
+	}
+
+	type plain Topology
+	return unmarshal((*plain)(t))
+}
@@ -0,0 +1,191 @@
+package netem
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/go-errors/errors"
+)
+
+// Device is the network interface of a node on the cluster network.
+const Device = "eth0"
+
+// maxLinkRules is the most rules with peers a node can have. Each gets a band of a prio qdisc,
+// after the 3 default bands, and prio supports 16.
+const maxLinkRules = 13
+
+// ratePattern matches the bandwidth units tc understands.
+var ratePattern = regexp.MustCompile(`(?i)^\d+(\.\d+)?[kmgt]?(bit|bps)$`)
+
+// Validate checks the rules for a cluster of n nodes.
+func Validate(rules []models.Netem, n int) []error {
+	var errs []error
+
+	for i, rule := range rules {
+		for _, err := range validateRule(rule, n) {
+			errs = append(errs, errors.Errorf("rule %d: %s", i, err.Error()))
+		}
+	}
+
+	for node := 0; node < n; node++ {
+		global, links := split(Rules(rules, node))
+		if len(global) > 1 {
+			errs = append(errs, errors.Errorf("node %d has %d rules without peers, only one is allowed", node, len(global)))
+		}
+		if len(links) > maxLinkRules {
+			errs = append(errs, errors.Errorf("node %d has %d rules with peers, at most %d are allowed", node, len(links), maxLinkRules))
+		}
+	}
+
+	return errs
+}
+
+func validateRule(rule models.Netem, n int) []error {
+	var errs []error
+
+	if rule.Delay == 0 && rule.Loss == 0 && rule.Rate == "" {
+		errs = append(errs, errors.Errorf("set at least one of delay, loss and rate"))
+	}
+	if rule.Delay < 0 || rule.Jitter < 0 {
+		errs = append(errs, errors.Errorf("delay and jitter must not be negative"))
+	}
+	if rule.Jitter > 0 && rule.Delay == 0 {
+		errs = append(errs, errors.Errorf("jitter needs a delay to vary"))
+	}
+	if rule.Loss < 0 || rule.Loss > 100 {
+		errs = append(errs, errors.Errorf("loss must be a percentage between 0 and 100, got %g", rule.Loss))
+	}
+	if rule.Rate != "" && !ratePattern.MatchString(rule.Rate) {
+		errs = append(errs, errors.Errorf("rate %q is not a bandwidth like 1mbit or 500kbit", rule.Rate))
+	}
+
+	for _, index := range append(append([]int{}, rule.Nodes...), rule.Peers...) {
+		if index < 0 || index >= n {
+			errs = append(errs, errors.Errorf("node %d is not one of the %d nodes", index, n))
+		}
+	}
+
+	return errs
+}
+
+// Rules returns the rules impairing the traffic of the node at index.
+func Rules(rules []models.Netem, index int) []models.Netem {
+	var result []models.Netem
+
+	for _, rule := range rules {
+		if len(rule.Nodes) == 0 {
+			result = append(result, rule)
+			continue
+		}
+		for _, node := range rule.Nodes {
+			if node == index {
+				result = append(result, rule)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// split separates the rules impairing all traffic from those impairing traffic to some peers.
+func split(rules []models.Netem) ([]models.Netem, []models.Netem) {
+	var global, links []models.Netem
+
+	for _, rule := range rules {
+		if len(rule.Peers) == 0 {
+			global = append(global, rule)
+		} else {
+			links = append(links, rule)
+		}
+	}
+
+	return global, links
+}
+
+// Commands returns the tc commands that apply the rules of a single node, given the IP address
+// of every node on the cluster network by index. A rule without peers is applied with a netem
+// qdisc at the root. Rules with peers each get a band of a prio qdisc, holding their netem
+// qdisc, with filters sending the traffic to their peers to it.
+func Commands(rules []models.Netem, ips map[int]string) ([][]string, error) {
+	global, links := split(rules)
+
+	if len(global) > 1 || len(links) > maxLinkRules {
+		return nil, errors.Errorf("too many rules for a single node")
+	}
+
+	if len(links) == 0 {
+		if len(global) == 0 {
+			return nil, nil
+		}
+		return [][]string{tc("qdisc", "add", "dev", Device, "root", "netem", netemArgs(global[0]))}, nil
+	}
+
+	commands := [][]string{
+		tc("qdisc", "add", "dev", Device, "root", "handle", "1:", "prio", "bands", strconv.Itoa(len(links)+3)),
+	}
+
+	if len(global) == 1 {
+		for band := 1; band <= 3; band++ {
+			commands = append(commands, tc("qdisc", "add", "dev", Device, "parent", fmt.Sprintf("1:%x", band), "netem", netemArgs(global[0])))
+		}
+	}
+
+	for i, rule := range links {
+		band := fmt.Sprintf("1:%x", i+4)
+		commands = append(commands, tc("qdisc", "add", "dev", Device, "parent", band, "handle", fmt.Sprintf("%x:", i+10), "netem", netemArgs(rule)))
+
+		for _, peer := range rule.Peers {
+			ip, ok := ips[peer]
+			if !ok || ip == "" {
+				return nil, errors.Errorf("node %d has no address on the cluster network", peer)
+			}
+			commands = append(commands, tc("filter", "add", "dev", Device, "protocol", "ip", "parent", "1:", "prio", "1", "u32", "match", "ip", "dst", ip+"/32", "flowid", band))
+		}
+	}
+
+	return commands, nil
+}
+
+// Script returns a shell command line that clears any impairment of the node and then runs
+// the given tc commands.
+func Script(commands [][]string) []string {
+	lines := []string{strings.Join(tc("qdisc", "del", "dev", Device, "root"), " ") + " 2>/dev/null || true"}
+	for _, command := range commands {
+		lines = append(lines, strings.Join(command, " "))
+	}
+
+	return []string{"sh", "-c", "set -e; " + strings.Join(lines, "; ")}
+}
+
+func tc(args ...string) []string {
+	var command []string
+	for _, arg := range append([]string{"tc"}, args...) {
+		command = append(command, strings.Fields(arg)...)
+	}
+
+	return command
+}
+
+// netemArgs returns the options of a netem qdisc applying the rule.
+func netemArgs(rule models.Netem) string {
+	var args []string
+
+	if rule.Delay > 0 {
+		args = append(args, "delay", fmt.Sprintf("%dus", rule.Delay.Nanoseconds()/1000))
+		if rule.Jitter > 0 {
+			args = append(args, fmt.Sprintf("%dus", rule.Jitter.Nanoseconds()/1000))
+		}
+	}
+	if rule.Loss > 0 {
+		args = append(args, "loss", strconv.FormatFloat(rule.Loss, 'f', -1, 64)+"%")
+	}
+	if rule.Rate != "" {
+		args = append(args, "rate", rule.Rate)
+	}
+
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,117 @@
+package netem
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+)
+
+var ips = map[int]string{0: "172.18.0.2", 1: "172.18.0.3", 2: "172.18.0.4"}
+
+func TestCommands_Global(t *testing.T) {
+	rules := []models.Netem{{Delay: 50 * time.Millisecond, Jitter: 10 * time.Millisecond, Loss: 0.5, Rate: "1mbit"}}
+
+	commands, err := Commands(rules, ips)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		strings.Fields("tc qdisc add dev eth0 root netem delay 50000us 10000us loss 0.5% rate 1mbit"),
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Expected %v, got %v", expected, commands)
+	}
+}
+
+func TestCommands_None(t *testing.T) {
+	commands, err := Commands(nil, ips)
+	if err != nil || commands != nil {
+		t.Errorf("Expected no commands, got %v, %v", commands, err)
+	}
+}
+
+func TestCommands_Links(t *testing.T) {
+	rules := []models.Netem{
+		{Loss: 5},
+		{Peers: []int{1, 2}, Delay: time.Second},
+	}
+
+	commands, err := Commands(rules, ips)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		strings.Fields("tc qdisc add dev eth0 root handle 1: prio bands 4"),
+		strings.Fields("tc qdisc add dev eth0 parent 1:1 netem loss 5%"),
+		strings.Fields("tc qdisc add dev eth0 parent 1:2 netem loss 5%"),
+		strings.Fields("tc qdisc add dev eth0 parent 1:3 netem loss 5%"),
+		strings.Fields("tc qdisc add dev eth0 parent 1:4 handle a: netem delay 1000000us"),
+		strings.Fields("tc filter add dev eth0 protocol ip parent 1: prio 1 u32 match ip dst 172.18.0.3/32 flowid 1:4"),
+		strings.Fields("tc filter add dev eth0 protocol ip parent 1: prio 1 u32 match ip dst 172.18.0.4/32 flowid 1:4"),
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Expected %v, got %v", expected, commands)
+	}
+
+	if _, err := Commands([]models.Netem{{Peers: []int{5}, Loss: 1}}, ips); err == nil {
+		t.Error("A peer without an address should have thrown an error...")
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules := []models.Netem{
+		{Loss: 1},
+		{Nodes: []int{1}, Loss: 2},
+		{Nodes: []int{0, 2}, Loss: 3},
+	}
+
+	if got := Rules(rules, 1); !reflect.DeepEqual(got, rules[:2]) {
+		t.Errorf("Expected %v, got %v", rules[:2], got)
+	}
+	if got := Rules(rules, 2); !reflect.DeepEqual(got, []models.Netem{rules[0], rules[2]}) {
+		t.Errorf("Expected the first and last rules, got %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []models.Netem{
+		{Delay: 10 * time.Millisecond},
+		{Nodes: []int{0}, Peers: []int{1}, Rate: "500kbit"},
+	}
+	if errs := Validate(valid, 2); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+
+	invalid := []struct {
+		rules    []models.Netem
+		expected string
+	}{
+		{[]models.Netem{{}}, "at least one"},
+		{[]models.Netem{{Jitter: time.Millisecond, Loss: 1}}, "jitter needs a delay"},
+		{[]models.Netem{{Loss: 101}}, "percentage"},
+		{[]models.Netem{{Rate: "fast"}}, "bandwidth"},
+		{[]models.Netem{{Nodes: []int{2}, Loss: 1}}, "node 2 is not one of the 2 nodes"},
+		{[]models.Netem{{Loss: 1}, {Nodes: []int{1}, Loss: 2}}, "only one is allowed"},
+	}
+
+	for _, tt := range invalid {
+		errs := Validate(tt.rules, 2)
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.expected) {
+			t.Errorf("Expected an error containing %q for %+v, got %v", tt.expected, tt.rules, errs)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	script := Script([][]string{strings.Fields("tc qdisc add dev eth0 root netem loss 1%")})
+
+	expected := []string{"sh", "-c", "set -e; tc qdisc del dev eth0 root 2>/dev/null || true; tc qdisc add dev eth0 root netem loss 1%"}
+	if !reflect.DeepEqual(script, expected) {
+		t.Errorf("Expected %v, got %v", expected, script)
+	}
+}
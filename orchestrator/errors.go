@@ -0,0 +1,24 @@
+package orchestrator
+
+import "fmt"
+
+// BuildError is returned when the Docker daemon reports a failure while building an image.
+type BuildError struct {
+	Image   string
+	Message string
+}
+
+func (e *BuildError) Error() string {
+	return fmt.Sprintf("building image %s: %s", e.Image, e.Message)
+}
+
+// NodeError is returned when a Docker operation on a single Swarm node fails.
+type NodeError struct {
+	Node string
+	Op   string
+	Err  error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("%s node %s: %s", e.Op, e.Node, e.Err.Error())
+}
@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/go-errors/errors"
+)
+
+// ClusterLabel is the label key holding the name of the cluster a resource belongs to.
+const ClusterLabel = "org.mfhq.swarmer.cluster"
+
+// HelperLabel is the label key holding the name of the cluster a throwaway helper container, such
+// as a netem or partition sidecar, works on. Helpers don't get the cluster label, so that they are
+// never taken for nodes.
+const HelperLabel = "org.mfhq.swarmer.helper"
+
+// DefaultCluster is the cluster name used when none is given.
+const DefaultCluster = "swarmer"
+
+// clusterNamePattern matches the names Docker accepts for containers and networks.
+var clusterNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidateClusterName returns an error if name can't be used to namespace Docker resources.
+func ValidateClusterName(name string) error {
+	if !clusterNamePattern.MatchString(name) {
+		return errors.Errorf("invalid cluster name %q, only letters, digits, '_', '.' and '-' are allowed and it must start with a letter or digit", name)
+	}
+
+	return nil
+}
+
+// Labels returns the labels identifying every resource of the given cluster.
+func Labels(cluster string) map[string]string {
+	return map[string]string{
+		DomainLabel:  DomainValue,
+		ClusterLabel: cluster,
+	}
+}
+
+// HelperLabels returns the labels of the throwaway helper containers run for the given cluster.
+func HelperLabels(cluster string) map[string]string {
+	return map[string]string{
+		DomainLabel: DomainValue,
+		HelperLabel: cluster,
+	}
+}
+
+// NetworkName returns the name of the bridge network the nodes of the given cluster share.
+func NetworkName(cluster string) string {
+	return cluster + "_swarm_network"
+}
+
+// ContainerName returns the name of the container of the node at index in the given cluster,
+// numbered from 0 like the node index label, e.g. swarmer_swarm_0 for the first node.
+func ContainerName(cluster string, index int) string {
+	return fmt.Sprintf("%s_swarm_%d", cluster, index)
+}
+
+// NodeIndex returns the index of the node from the labels of its container, and false if the
+// container isn't labelled with one.
+func NodeIndex(labels map[string]string) (int, bool) {
+	index, err := strconv.Atoi(labels[NodeLabel])
+	if err != nil {
+		return 0, false
+	}
+
+	return index, true
+}
@@ -0,0 +1,41 @@
+package orchestrator
+
+import "testing"
+
+func TestValidateClusterName(t *testing.T) {
+	for _, name := range []string{"swarmer", "ci-42", "project_a.dev", "0"} {
+		if err := ValidateClusterName(name); err != nil {
+			t.Errorf("Cluster name %q should be valid: %s", name, err.Error())
+		}
+	}
+
+	for _, name := range []string{"", "-leading", "has space", "slash/name"} {
+		if err := ValidateClusterName(name); err == nil {
+			t.Errorf("Cluster name %q should have thrown an error...", name)
+		}
+	}
+}
+
+func TestNaming(t *testing.T) {
+	if NetworkName("ci") != "ci_swarm_network" {
+		t.Errorf("Unexpected network name %s", NetworkName("ci"))
+	}
+	if ContainerName("ci", 0) != "ci_swarm_0" {
+		t.Errorf("Unexpected container name %s", ContainerName("ci", 0))
+	}
+	if Labels("ci")[ClusterLabel] != "ci" {
+		t.Error("Cluster labels should carry the cluster name")
+	}
+	if _, ok := HelperLabels("ci")[ClusterLabel]; ok || HelperLabels("ci")[HelperLabel] != "ci" {
+		t.Error("Helper labels should carry the cluster name without the cluster label")
+	}
+}
+
+func TestNodeIndex(t *testing.T) {
+	if index, ok := NodeIndex(map[string]string{NodeLabel: "3"}); !ok || index != 3 {
+		t.Errorf("Expected node index 3, got %d", index)
+	}
+	if _, ok := NodeIndex(map[string]string{}); ok {
+		t.Error("A container without a node label should have no index")
+	}
+}
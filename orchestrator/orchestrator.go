@@ -0,0 +1,494 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/MainframeHQ/swarmer/util"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// DomainLabel is the label key set on every Docker resource swarmer creates.
+const DomainLabel = "org.mfhq.domain"
+
+// DomainValue is the value of DomainLabel for Swarm resources.
+const DomainValue = "swarm"
+
+// NodeLabel is the label key holding the index of a node within the cluster.
+const NodeLabel = "org.mfhq.swarmer.node"
+
+// HashLabel is the label key holding the hash of the settings and image a node was started with.
+const HashLabel = "org.mfhq.swarmer.hash"
+
+// RepoLabel is the label key holding the Git repository a Swarm image was built from.
+const RepoLabel = "org.mfhq.swarmer.repo"
+
+// CommitLabel is the label key holding the commit a Swarm image was built from.
+const CommitLabel = "org.mfhq.swarmer.commit"
+
+// Ports are the container ports published on random host ports for every Swarm node.
+var Ports = []string{"8500/tcp", "8545/tcp", "8546/tcp", "30399/tcp", "30301/tcp", "30303/tcp"}
+
+// NodeSpec describes a single Swarm node container.
+type NodeSpec struct {
+	Name    string
+	Image   string
+	Network string
+	Cmd     []string
+	Env     []string
+	Labels  map[string]string
+	Binds   []string
+	// NanoCPUs and Memory limit the container's CPU and memory, 0 meaning unlimited.
+	NanoCPUs int64
+	Memory   int64
+}
+
+// BuildSpec describes a Swarm image to build.
+type BuildSpec struct {
+	// Context are the directories sent to Docker as the build context.
+	Context []util.ContextDir
+	// Files are single files added to the build context.
+	Files []util.ContextFile
+	// Dockerfile is the path of the Dockerfile within the context, empty meaning "Dockerfile".
+	Dockerfile string
+	Tag        string
+	Labels     map[string]string
+	Args       map[string]string
+	// NoCache rebuilds every step instead of reusing cached layers.
+	NoCache bool
+}
+
+// IOrchestrator is the interface for managing Swarm node containers through the Docker API.
+type IOrchestrator interface {
+	CreateNetwork(ctx context.Context, name string, labels map[string]string) (string, error)
+	BuildImage(ctx context.Context, spec BuildSpec, buildLog io.Writer) error
+	PullImage(ctx context.Context, ref string, pullLog io.Writer) error
+	ImageID(ctx context.Context, tag string) (string, error)
+	RemoveContainers(ctx context.Context, labels map[string]string, removeVolumes bool) ([]string, []string, error)
+	RemoveNetworks(ctx context.Context, labels map[string]string) ([]string, error)
+	ContainerImages(ctx context.Context, labels map[string]string) ([]string, error)
+	RunNode(ctx context.Context, spec NodeSpec) (string, error)
+	ListNodes(ctx context.Context, labels map[string]string) ([]types.ContainerJSON, error)
+	StopNode(ctx context.Context, id string) error
+	RunSidecar(ctx context.Context, target string, image string, labels map[string]string, cmd []string) (string, error)
+	RunCommand(ctx context.Context, image string, labels map[string]string, cmd []string) (string, error)
+}
+
+// Orchestrator is the struct for this implementation of IOrchestrator.
+type Orchestrator struct {
+	dockerClient *client.Client
+}
+
+// GetOrchestrator returns a pointer to a new instance of this implementation of IOrchestrator.
+func GetOrchestrator(d *client.Client) *Orchestrator {
+	var o = Orchestrator{
+		dockerClient: d,
+	}
+
+	return &o
+}
+
+// buildMessage is a single line of the JSON stream returned by the Docker build endpoint.
+type buildMessage struct {
+	Stream      string `json:"stream"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	ErrorDetail struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// CreateNetwork creates a bridge network with the given name, or returns the ID of the
+// existing network if one by that name is already present.
+func (o *Orchestrator) CreateNetwork(ctx context.Context, name string, labels map[string]string) (string, error) {
+	options := types.NetworkListOptions{Filters: filters.NewArgs()}
+	options.Filters.Add("name", name)
+
+	networks, err := o.dockerClient.NetworkList(ctx, options)
+	if err != nil {
+		return "", err
+	}
+
+	for _, n := range networks {
+		if n.Name == name {
+			return n.ID, nil
+		}
+	}
+
+	resp, err := o.dockerClient.NetworkCreate(ctx, name, types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         "bridge",
+		Labels:         labels,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ID, nil
+}
+
+// BuildImage builds the image described by spec, writing the build output to buildLog. A failed
+// build step is returned as a *BuildError.
+func (o *Orchestrator) BuildImage(ctx context.Context, spec BuildSpec, buildLog io.Writer) error {
+	buildContext, err := util.TarContext(spec.Context, spec.Files)
+	if err != nil {
+		return err
+	}
+	if closer, ok := buildContext.(io.Closer); ok {
+		// stops writing the context if Docker stops reading it
+		defer closer.Close()
+	}
+
+	args := map[string]*string{}
+	for k, v := range spec.Args {
+		value := v
+		args[k] = &value
+	}
+
+	resp, err := o.dockerClient.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Tags:        []string{spec.Tag},
+		Dockerfile:  spec.Dockerfile,
+		BuildArgs:   args,
+		Remove:      true,
+		ForceRemove: true,
+		NoCache:     spec.NoCache,
+		Labels:      spec.Labels,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return readMessages(resp.Body, spec.Tag, buildLog)
+}
+
+// PullImage pulls the image with the given reference, writing the progress to pullLog.
+func (o *Orchestrator) PullImage(ctx context.Context, ref string, pullLog io.Writer) error {
+	body, err := o.dockerClient.ImagePull(ctx, ref, types.ImagePullOptions{})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return readMessages(body, ref, pullLog)
+}
+
+// readMessages copies the JSON message stream of a build or pull of image to log, returning the
+// first error reported in the stream as a *BuildError.
+func readMessages(stream io.Reader, image string, log io.Writer) error {
+	decoder := json.NewDecoder(stream)
+	for {
+		var msg buildMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if msg.Error != "" {
+			message := msg.ErrorDetail.Message
+			if message == "" {
+				message = msg.Error
+			}
+			return &BuildError{Image: image, Message: message}
+		}
+
+		line := msg.Stream
+		if msg.Status != "" {
+			line += msg.Status + "\n"
+		}
+		if _, err := io.WriteString(log, line); err != nil {
+			return err
+		}
+	}
+}
+
+// ImageID returns the ID of the image with the given tag, or an empty string if there is none.
+func (o *Orchestrator) ImageID(ctx context.Context, tag string) (string, error) {
+	image, _, err := o.dockerClient.ImageInspectWithRaw(ctx, tag)
+	if client.IsErrNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return image.ID, nil
+}
+
+// RemoveContainers force removes every container, running or not, carrying all of the given
+// labels and returns the IDs of the removed containers. If removeVolumes is set the volumes
+// mounted into those containers are removed too and their names returned.
+func (o *Orchestrator) RemoveContainers(ctx context.Context, labels map[string]string, removeVolumes bool) ([]string, []string, error) {
+	options := types.ContainerListOptions{All: true, Filters: labelFilters(labels)}
+
+	containers, err := o.dockerClient.ContainerList(ctx, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var removed []string
+	var volumes []string
+	for _, c := range containers {
+		err := o.dockerClient.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true})
+		if err != nil {
+			return removed, volumes, &NodeError{Node: c.ID, Op: "removing", Err: err}
+		}
+		removed = append(removed, c.ID)
+
+		if !removeVolumes {
+			continue
+		}
+
+		for _, m := range c.Mounts {
+			if m.Type != mount.TypeVolume {
+				continue
+			}
+			if err := o.dockerClient.VolumeRemove(ctx, m.Name, true); err != nil {
+				return removed, volumes, err
+			}
+			volumes = append(volumes, m.Name)
+		}
+	}
+
+	return removed, volumes, nil
+}
+
+// RemoveNetworks removes every network carrying all of the given labels and returns their names.
+func (o *Orchestrator) RemoveNetworks(ctx context.Context, labels map[string]string) ([]string, error) {
+	networks, err := o.dockerClient.NetworkList(ctx, types.NetworkListOptions{Filters: labelFilters(labels)})
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, n := range networks {
+		if err := o.dockerClient.NetworkRemove(ctx, n.ID); err != nil {
+			return removed, err
+		}
+		removed = append(removed, n.Name)
+	}
+
+	return removed, nil
+}
+
+// ContainerImages returns the IDs of the images of every container, running or not, carrying all
+// of the given labels.
+func (o *Orchestrator) ContainerImages(ctx context.Context, labels map[string]string) ([]string, error) {
+	containers, err := o.dockerClient.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: labelFilters(labels)})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var ids []string
+	for _, c := range containers {
+		if !seen[c.ImageID] {
+			seen[c.ImageID] = true
+			ids = append(ids, c.ImageID)
+		}
+	}
+
+	return ids, nil
+}
+
+// RunNode creates and starts a container from the given spec, publishing every port in Ports
+// on a random host port, and returns the new container ID.
+func (o *Orchestrator) RunNode(ctx context.Context, spec NodeSpec) (string, error) {
+	exposed := nat.PortSet{}
+	bindings := nat.PortMap{}
+	for _, p := range Ports {
+		port := nat.Port(p)
+		exposed[port] = struct{}{}
+		bindings[port] = []nat.PortBinding{{}}
+	}
+
+	config := &container.Config{
+		Image:        spec.Image,
+		Cmd:          spec.Cmd,
+		Env:          spec.Env,
+		Labels:       spec.Labels,
+		ExposedPorts: exposed,
+	}
+	hostConfig := &container.HostConfig{
+		Binds:        spec.Binds,
+		PortBindings: bindings,
+		Resources: container.Resources{
+			NanoCPUs: spec.NanoCPUs,
+			Memory:   spec.Memory,
+		},
+	}
+	networkingConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			spec.Network: {},
+		},
+	}
+
+	created, err := o.dockerClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, spec.Name)
+	if err != nil {
+		return "", &NodeError{Node: spec.Name, Op: "creating", Err: err}
+	}
+
+	err = o.dockerClient.ContainerStart(ctx, created.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return created.ID, &NodeError{Node: spec.Name, Op: "starting", Err: err}
+	}
+
+	return created.ID, nil
+}
+
+// labelFilters returns filters matching resources that carry all of the given labels.
+func labelFilters(labels map[string]string) filters.Args {
+	args := filters.NewArgs()
+	for k, v := range labels {
+		args.Add("label", k+"="+v)
+	}
+
+	return args
+}
+
+// ListNodes returns the details of every running node container carrying all of the given
+// labels, ordered by node index. Containers without a node index are left out.
+func (o *Orchestrator) ListNodes(ctx context.Context, labels map[string]string) ([]types.ContainerJSON, error) {
+	args := labelFilters(labels)
+	args.Add("status", "running")
+
+	containers, err := o.dockerClient.ContainerList(ctx, types.ContainerListOptions{Filters: args})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []types.ContainerJSON
+	for _, c := range containers {
+		if _, ok := NodeIndex(c.Labels); !ok {
+			continue
+		}
+
+		node, err := o.dockerClient.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return nil, &NodeError{Node: c.ID, Op: "inspecting", Err: err}
+		}
+		nodes = append(nodes, node)
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, _ := NodeIndex(nodes[i].Config.Labels)
+		b, _ := NodeIndex(nodes[j].Config.Labels)
+		return a < b
+	})
+
+	return nodes, nil
+}
+
+// StopNode stops the node container with the given ID, giving it the usual grace period to shut
+// down, then removes it along with its volumes.
+func (o *Orchestrator) StopNode(ctx context.Context, id string) error {
+	if err := o.dockerClient.ContainerStop(ctx, id, nil); err != nil {
+		return &NodeError{Node: id, Op: "stopping", Err: err}
+	}
+
+	err := o.dockerClient.ContainerRemove(ctx, id, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+	if err != nil {
+		return &NodeError{Node: id, Op: "removing", Err: err}
+	}
+
+	return nil
+}
+
+// RunSidecar runs cmd to completion in a throwaway container of the given image that shares the
+// network namespace of the target container and may administer its network, and returns the
+// output. A non-zero exit status is returned as a *NodeError along with the output.
+func (o *Orchestrator) RunSidecar(ctx context.Context, target string, image string, labels map[string]string, cmd []string) (string, error) {
+	config := &container.Config{
+		Image:  image,
+		Cmd:    cmd,
+		Labels: labels,
+	}
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode("container:" + target),
+		CapAdd:      []string{"NET_ADMIN"},
+	}
+
+	output, step, err := o.runToExit(ctx, config, hostConfig)
+	if err != nil {
+		return output, &NodeError{Node: target, Op: step + " sidecar of", Err: err}
+	}
+
+	return output, nil
+}
+
+// RunCommand runs cmd to completion in a throwaway container of the given image, without a
+// network, and returns the output. A non-zero exit status is returned as an error along with the
+// output.
+func (o *Orchestrator) RunCommand(ctx context.Context, image string, labels map[string]string, cmd []string) (string, error) {
+	config := &container.Config{
+		Image:      image,
+		Entrypoint: []string{},
+		Cmd:        cmd,
+		Labels:     labels,
+	}
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode("none"),
+	}
+
+	output, step, err := o.runToExit(ctx, config, hostConfig)
+	if err != nil {
+		return output, errors.Errorf("%s container of image %s: %s", step, image, err.Error())
+	}
+
+	return output, nil
+}
+
+// runToExit creates, starts and waits for a container, removes it and returns its output. An
+// error is returned with the step that failed, and a non-zero exit status as an error of the
+// "running" step.
+func (o *Orchestrator) runToExit(ctx context.Context, config *container.Config, hostConfig *container.HostConfig) (string, string, error) {
+	created, err := o.dockerClient.ContainerCreate(ctx, config, hostConfig, nil, "")
+	if err != nil {
+		return "", "creating", err
+	}
+	// the container is removed even if ctx was cancelled
+	defer o.dockerClient.ContainerRemove(context.Background(), created.ID, types.ContainerRemoveOptions{Force: true})
+
+	waitC, errC := o.dockerClient.ContainerWait(ctx, created.ID, container.WaitConditionNextExit)
+
+	if err := o.dockerClient.ContainerStart(ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "starting", err
+	}
+
+	var status int64
+	select {
+	case result := <-waitC:
+		status = result.StatusCode
+	case err := <-errC:
+		return "", "waiting for", err
+	}
+
+	logs, err := o.dockerClient.ContainerLogs(ctx, created.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return "", "reading output of", err
+	}
+	defer logs.Close()
+
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, logs); err != nil {
+		return "", "reading output of", err
+	}
+
+	if status != 0 {
+		return output.String(), "running", errors.Errorf("exit status %d: %s", status, strings.TrimSpace(output.String()))
+	}
+
+	return output.String(), "", nil
+}
@@ -0,0 +1,168 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/go-errors/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Supported output formats. Template formats take the template, or the path to a file holding
+// it, after an equals sign, e.g. `template={{(index . 0).GatewayPort}}`.
+const (
+	JSON         = "json"
+	YAML         = "yaml"
+	Dotenv       = "dotenv"
+	Export       = "export"
+	Template     = "template"
+	TemplateFile = "template-file"
+)
+
+// Validate returns an error if format is not a supported output format, or holds a template
+// that does not parse.
+func Validate(format string) error {
+	_, err := parse(format)
+
+	return err
+}
+
+// Nodes writes the given node details to w in the given format.
+func Nodes(w io.Writer, format string, nodes []models.NodeInfo) error {
+	tmpl, err := parse(format)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		return tmpl.Execute(w, nodes)
+	}
+
+	switch format {
+	case Dotenv:
+		for _, v := range nodeVars(nodes) {
+			fmt.Fprintf(w, "%s=%s\n", v[0], v[1])
+		}
+		return nil
+
+	case Export:
+		for _, v := range nodeVars(nodes) {
+			fmt.Fprintf(w, "export %s='%s'\n", v[0], strings.Replace(v[1], "'", `'\''`, -1))
+		}
+		return nil
+	}
+
+	return Value(w, format, nodes)
+}
+
+// Value writes any value to w as JSON, YAML or through a template. Formats that only make
+// sense for node details, like dotenv, fall back to JSON.
+func Value(w io.Writer, format string, v interface{}) error {
+	tmpl, err := parse(format)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		return tmpl.Execute(w, v)
+	}
+
+	var data []byte
+	if format == YAML {
+		data, err = yaml.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// parse checks format and returns the parsed template for template formats, or nil otherwise.
+func parse(format string) (*template.Template, error) {
+	tokens := strings.SplitN(format, "=", 2)
+
+	switch tokens[0] {
+	case "", JSON, YAML, Dotenv, Export:
+		if len(tokens) == 1 {
+			return nil, nil
+		}
+
+	case Template, TemplateFile:
+		if len(tokens) == 1 {
+			return nil, errors.Errorf("output format %s needs a value, e.g. %s=...", tokens[0], tokens[0])
+		}
+
+		text := tokens[1]
+		if tokens[0] == TemplateFile {
+			data, err := ioutil.ReadFile(tokens[1])
+			if err != nil {
+				return nil, err
+			}
+			text = string(data)
+		}
+
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return nil, errors.Errorf("invalid output template: %s", err.Error())
+		}
+		return tmpl, nil
+	}
+
+	return nil, errors.Errorf("unknown output format %q, expected one of %s, %s, %s, %s, %s=... or %s=...", format, JSON, YAML, Dotenv, Export, Template, TemplateFile)
+}
+
+// Environment returns the variables describing the given nodes as KEY=value pairs, the way
+// the dotenv format writes them, for the environment of a child process.
+func Environment(nodes []models.NodeInfo) []string {
+	var env []string
+	for _, v := range nodeVars(nodes) {
+		env = append(env, v[0]+"="+v[1])
+	}
+
+	return env
+}
+
+// nodeVars returns the environment variable names and values describing the given nodes.
+func nodeVars(nodes []models.NodeInfo) [][2]string {
+	var gateways, websockets []string
+	for _, node := range nodes {
+		gateways = append(gateways, "http://localhost:"+node.GatewayPort)
+		websockets = append(websockets, "ws://localhost:"+node.WebsocketPort)
+	}
+
+	vars := [][2]string{
+		{"SWARM_NODES", fmt.Sprint(len(nodes))},
+		{"SWARM_GATEWAYS", strings.Join(gateways, ",")},
+		{"SWARM_WS_ENDPOINTS", strings.Join(websockets, ",")},
+	}
+
+	for i, node := range nodes {
+		prefix := fmt.Sprintf("SWARM_NODE_%d_", i)
+		vars = append(vars,
+			[2]string{prefix + "COMM_PORT", node.CommPort},
+			[2]string{prefix + "GATEWAY_PORT", node.GatewayPort},
+			[2]string{prefix + "WEBSOCKET_PORT", node.WebsocketPort},
+			[2]string{prefix + "ADMIN_PORT", node.AdminPort},
+			[2]string{prefix + "GATEWAY_URL", "http://localhost:" + node.GatewayPort},
+			[2]string{prefix + "WEBSOCKET_URL", "ws://localhost:" + node.WebsocketPort},
+			[2]string{prefix + "ENODE", node.Enode},
+			[2]string{prefix + "ENR", node.Enr},
+			[2]string{prefix + "ID", node.ID},
+			[2]string{prefix + "NAME", node.Name},
+			[2]string{prefix + "CONTAINER_ID", node.ContainerID},
+			[2]string{prefix + "CONTAINER_NAMES", strings.Join(node.ContainerNames, ",")},
+			[2]string{prefix + "IP", node.IPAddress},
+		)
+	}
+
+	return vars
+}
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/MainframeHQ/swarmer/models"
+)
+
+var nodes = []models.NodeInfo{
+	{GatewayPort: "32768", WebsocketPort: "32769", Enode: "enode://abc@10.0.0.2:30303?discport=0", ContainerNames: []string{"swarmer_swarm_0"}},
+	{GatewayPort: "32770", Name: "it's"},
+}
+
+func TestValidate(t *testing.T) {
+	for _, format := range []string{"", JSON, YAML, Dotenv, Export, "template={{len .}}"} {
+		if err := Validate(format); err != nil {
+			t.Errorf("Output format %q should be valid: %s", format, err.Error())
+		}
+	}
+
+	for _, format := range []string{"xml", "template", "template={{", "json=1", "template-file=non existent file"} {
+		if err := Validate(format); err == nil {
+			t.Errorf("Output format %q should have thrown an error...", format)
+		}
+	}
+}
+
+func TestNodes(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{JSON, []string{`"gateway_port": "32768"`}},
+		{YAML, []string{"- comm_port: \"\"\n  gateway_port: \"32768\""}},
+		{Dotenv, []string{"SWARM_NODES=2\n", "SWARM_GATEWAYS=http://localhost:32768,http://localhost:32770\n", "SWARM_NODE_0_GATEWAY_PORT=32768\n", "SWARM_NODE_0_GATEWAY_URL=http://localhost:32768\n", "SWARM_NODE_1_GATEWAY_PORT=32770\n"}},
+		{Export, []string{"export SWARM_NODE_0_ENODE='enode://abc@10.0.0.2:30303?discport=0'\n", `export SWARM_NODE_1_NAME='it'\''s'`}},
+		{"template={{range .}}{{.GatewayPort}} {{end}}", []string{"32768 32770 "}},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Nodes(&buf, test.format, nodes); err != nil {
+			t.Errorf("%s: unexpected error %s", test.format, err.Error())
+			continue
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(buf.String(), expected) {
+				t.Errorf("%s: output should contain %q, got:\n%s", test.format, expected, buf.String())
+			}
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Value(&buf, Dotenv, models.ClusterInfo{Name: "ci"}); err != nil {
+		t.Fatalf("Unexpected error %s", err.Error())
+	}
+	if !strings.Contains(buf.String(), `"name": "ci"`) {
+		t.Errorf("Formats other than yaml and templates should fall back to JSON, got:\n%s", buf.String())
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	env := Environment(nodes)
+
+	for _, expected := range []string{"SWARM_NODES=2", "SWARM_WS_ENDPOINTS=ws://localhost:32769,ws://localhost:", "SWARM_NODE_1_NAME=it's"} {
+		found := false
+		for _, v := range env {
+			if strings.HasPrefix(v, expected) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Environment should contain %q, got %v", expected, env)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package partition
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// Chain is the iptables chain holding the rules that cut a node off from the other groups.
+const Chain = "SWARMER-PARTITION"
+
+// namePattern matches the group names that are safe to use in a shell command line.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Group is a set of nodes that can only reach each other while the cluster is partitioned.
+type Group struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+// ParseGroups parses a partition layout such as 0,1,2/3,4, where groups are separated by slashes
+// and may be named, as in left=0,1,2/right=3,4. Unnamed groups are named after their position.
+func ParseGroups(spec string) ([]Group, error) {
+	var groups []Group
+
+	for i, part := range strings.Split(spec, "/") {
+		group := Group{Name: strconv.Itoa(i)}
+
+		if eq := strings.Index(part, "="); eq >= 0 {
+			group.Name = strings.TrimSpace(part[:eq])
+			part = part[eq+1:]
+		}
+		if !namePattern.MatchString(group.Name) {
+			return nil, errors.Errorf("group name %q may only contain letters, digits, '.', '_' and '-'", group.Name)
+		}
+
+		for _, field := range strings.Split(part, ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return nil, errors.Errorf("group %s: %q is not a node index", group.Name, field)
+			}
+			group.Nodes = append(group.Nodes, index)
+		}
+
+		groups = append(groups, group)
+	}
+
+	if len(groups) < 2 {
+		return nil, errors.Errorf("a partition needs at least two groups separated by '/'")
+	}
+
+	return groups, nil
+}
+
+// Validate checks that the groups have distinct names and hold every one of the given node
+// indexes exactly once.
+func Validate(groups []Group, indexes []int) error {
+	names := map[string]bool{}
+	seen := map[int]string{}
+	running := map[int]bool{}
+	for _, index := range indexes {
+		running[index] = true
+	}
+
+	for _, group := range groups {
+		if names[group.Name] {
+			return errors.Errorf("group %s is given more than once", group.Name)
+		}
+		names[group.Name] = true
+
+		for _, index := range group.Nodes {
+			if !running[index] {
+				return errors.Errorf("node %d is not running", index)
+			}
+			if other, ok := seen[index]; ok {
+				return errors.Errorf("node %d is in both group %s and group %s", index, other, group.Name)
+			}
+			seen[index] = group.Name
+		}
+	}
+
+	var missing []string
+	for _, index := range indexes {
+		if _, ok := seen[index]; !ok {
+			missing = append(missing, strconv.Itoa(index))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("nodes %s are not in any group", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// GroupOf returns the name of the group holding the node at index, or an empty string if there
+// is none.
+func GroupOf(groups []Group, index int) string {
+	for _, group := range groups {
+		for _, node := range group.Nodes {
+			if node == index {
+				return group.Name
+			}
+		}
+	}
+
+	return ""
+}
+
+// Script returns a shell command line that cuts the node at index off from every node outside
+// its group, given the IP address of every node on the cluster network by index.
+func Script(groups []Group, index int, ips map[int]string) ([]string, error) {
+	own := GroupOf(groups, index)
+	if own == "" {
+		return nil, errors.Errorf("node %d is not in any group", index)
+	}
+
+	lines := append(heal(),
+		"iptables -N "+Chain,
+		fmt.Sprintf("iptables -A %s -m comment --comment swarmer-group=%s", Chain, own),
+	)
+
+	for _, group := range groups {
+		if group.Name == own {
+			continue
+		}
+		for _, node := range group.Nodes {
+			ip, ok := ips[node]
+			if !ok || ip == "" {
+				return nil, errors.Errorf("node %d has no address on the cluster network", node)
+			}
+			lines = append(lines,
+				fmt.Sprintf("iptables -A %s -s %s/32 -j DROP", Chain, ip),
+				fmt.Sprintf("iptables -A %s -d %s/32 -j DROP", Chain, ip),
+			)
+		}
+	}
+
+	lines = append(lines,
+		"iptables -I INPUT -j "+Chain,
+		"iptables -I OUTPUT -j "+Chain,
+	)
+
+	return []string{"sh", "-c", "set -e; " + strings.Join(lines, "; ")}, nil
+}
+
+// HealScript returns a shell command line that removes the partition rules of a node.
+func HealScript() []string {
+	return []string{"sh", "-c", strings.Join(heal(), "; ")}
+}
+
+// heal returns the commands removing the chain, which succeed whether it exists or not.
+func heal() []string {
+	return []string{
+		"iptables -D INPUT -j " + Chain + " 2>/dev/null || true",
+		"iptables -D OUTPUT -j " + Chain + " 2>/dev/null || true",
+		"iptables -F " + Chain + " 2>/dev/null || true",
+		"iptables -X " + Chain + " 2>/dev/null || true",
+	}
+}
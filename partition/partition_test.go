@@ -0,0 +1,87 @@
+package partition
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGroups(t *testing.T) {
+	groups, err := ParseGroups("0,1,2/3, 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Group{{Name: "0", Nodes: []int{0, 1, 2}}, {Name: "1", Nodes: []int{3, 4}}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+
+	groups, err = ParseGroups("left=0,1/right=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups[0].Name != "left" || groups[1].Name != "right" {
+		t.Errorf("Expected named groups, got %v", groups)
+	}
+
+	for _, spec := range []string{"", "0,1,2", "0,a/1", "bad name=0/1"} {
+		if _, err := ParseGroups(spec); err == nil {
+			t.Errorf("Parsing %q should have thrown an error...", spec)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	groups := []Group{{Name: "a", Nodes: []int{0, 1}}, {Name: "b", Nodes: []int{2}}}
+	if err := Validate(groups, []int{0, 1, 2}); err != nil {
+		t.Errorf("Groups should be valid: %s", err.Error())
+	}
+
+	tests := []struct {
+		groups   []Group
+		expected string
+	}{
+		{[]Group{{Name: "a", Nodes: []int{0, 1}}, {Name: "a", Nodes: []int{2}}}, "more than once"},
+		{[]Group{{Name: "a", Nodes: []int{0, 1}}, {Name: "b", Nodes: []int{1, 2}}}, "both group a and group b"},
+		{[]Group{{Name: "a", Nodes: []int{0, 1}}, {Name: "b", Nodes: []int{3}}}, "not running"},
+		{[]Group{{Name: "a", Nodes: []int{0}}, {Name: "b", Nodes: []int{2}}}, "nodes 1 are not in any group"},
+	}
+
+	for _, test := range tests {
+		err := Validate(test.groups, []int{0, 1, 2})
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Expected an error containing %q for %v, got %v", test.expected, test.groups, err)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	groups := []Group{{Name: "a", Nodes: []int{0, 1}}, {Name: "b", Nodes: []int{2}}}
+	ips := map[int]string{0: "172.18.0.2", 1: "172.18.0.3", 2: "172.18.0.4"}
+
+	script, err := Script(groups, 0, ips)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := script[2]
+	for _, expected := range []string{
+		"--comment swarmer-group=a",
+		"-s 172.18.0.4/32 -j DROP",
+		"-d 172.18.0.4/32 -j DROP",
+		"iptables -I INPUT -j SWARMER-PARTITION",
+		"iptables -I OUTPUT -j SWARMER-PARTITION",
+	} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("Expected %q in %s", expected, line)
+		}
+	}
+	if strings.Contains(line, "172.18.0.3") {
+		t.Errorf("Nodes of the same group shouldn't be cut off: %s", line)
+	}
+
+	if _, err := Script(groups, 5, ips); err == nil {
+		t.Error("A node outside the groups should have thrown an error...")
+	}
+}
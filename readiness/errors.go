@@ -0,0 +1,50 @@
+package readiness
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NodeStatus is the outcome of waiting on a single node.
+type NodeStatus struct {
+	Name     string
+	Ready    bool
+	Attempts int
+	// Failures maps the name of each probe that has not yet passed to its last error.
+	Failures map[string]string
+}
+
+// Error is returned by WaitAll when one or more nodes never became ready.
+type Error struct {
+	Timeout time.Duration
+	Nodes   []NodeStatus
+}
+
+func (e *Error) Error() string {
+	var notReady int
+	var lines []string
+	for _, node := range e.Nodes {
+		if node.Ready {
+			lines = append(lines, fmt.Sprintf("  %s: ready", node.Name))
+			continue
+		}
+		notReady++
+
+		var probes []string
+		for probe := range node.Failures {
+			probes = append(probes, probe)
+		}
+		sort.Strings(probes)
+
+		var failures []string
+		for _, probe := range probes {
+			failures = append(failures, probe+": "+node.Failures[probe])
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s: not ready after %d attempts (%s)", node.Name, node.Attempts, strings.Join(failures, "; ")))
+	}
+
+	return fmt.Sprintf("%d of %d nodes did not become ready within %s\n%s", notReady, len(e.Nodes), e.Timeout, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,168 @@
+package readiness
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/admin"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/net/context"
+)
+
+// DefaultTimeout is how long to wait for every node to become ready when no timeout is configured.
+// Swarm is built into the image before any node starts, so the nodes only have to start it.
+const DefaultTimeout = 2 * time.Minute
+
+// DefaultBackoff is the initial delay between probes of a node that is not yet ready.
+const DefaultBackoff = time.Second
+
+// maxBackoff caps the per-node delay between probes.
+const maxBackoff = 30 * time.Second
+
+// Names of the probes run against each node.
+const (
+	ProbeAdmin     = "admin"
+	ProbeGateway   = "gateway"
+	ProbeWebsocket = "websocket"
+)
+
+// Target holds the host ports of a single node to be probed.
+type Target struct {
+	Name          string
+	AdminPort     string
+	GatewayPort   string
+	WebsocketPort string
+}
+
+// IChecker is the interface for waiting on Swarm nodes to become ready.
+type IChecker interface {
+	WaitAll(ctx context.Context, targets []Target, timeout time.Duration, backoff time.Duration) error
+}
+
+// Checker is the struct for this implementation of IChecker.
+type Checker struct {
+	adminClient admin.IClient
+	httpClient  *http.Client
+}
+
+// GetChecker returns a pointer to a new instance of this implementation of IChecker.
+func GetChecker(a admin.IClient) *Checker {
+	var c = Checker{
+		adminClient: a,
+		httpClient:  &http.Client{},
+	}
+
+	return &c
+}
+
+// WaitAll probes the admin RPC, HTTP gateway and websocket endpoints of every target until each
+// one answers. Every node backs off independently, starting at backoff and doubling after each
+// failed round. If any node is still not ready once timeout has elapsed an *Error is returned
+// describing every node.
+func (c *Checker) WaitAll(ctx context.Context, targets []Target, timeout time.Duration, backoff time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	statuses := make([]NodeStatus, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			statuses[i] = c.waitNode(ctx, target, backoff)
+		}(i, target)
+	}
+	wg.Wait()
+
+	for _, status := range statuses {
+		if !status.Ready {
+			return &Error{Timeout: timeout, Nodes: statuses}
+		}
+	}
+
+	return nil
+}
+
+// waitNode probes a single target until it is ready or ctx is done.
+func (c *Checker) waitNode(ctx context.Context, target Target, backoff time.Duration) NodeStatus {
+	status := NodeStatus{Name: target.Name, Failures: map[string]string{}}
+
+	probes := map[string]func(context.Context, Target) error{
+		ProbeAdmin:     c.probeAdmin,
+		ProbeGateway:   c.probeGateway,
+		ProbeWebsocket: c.probeWebsocket,
+	}
+	for name := range probes {
+		status.Failures[name] = "not probed"
+	}
+
+	for {
+		status.Attempts++
+
+		for name, probe := range probes {
+			if err := probe(ctx, target); err != nil {
+				status.Failures[name] = err.Error()
+				continue
+			}
+			delete(status.Failures, name)
+			delete(probes, name)
+		}
+
+		if len(probes) == 0 {
+			status.Ready = true
+			return status
+		}
+
+		select {
+		case <-ctx.Done():
+			return status
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// probeAdmin succeeds once the node answers admin_nodeInfo over RPC.
+func (c *Checker) probeAdmin(ctx context.Context, target Target) error {
+	conn, err := c.adminClient.GetConnection("http://localhost:" + target.AdminPort)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var info map[string]interface{}
+
+	return conn.CallContext(ctx, &info, "admin_nodeInfo")
+}
+
+// probeGateway succeeds once the HTTP gateway returns any response.
+func (c *Checker) probeGateway(ctx context.Context, target Target) error {
+	req, err := http.NewRequest("GET", "http://localhost:"+target.GatewayPort+"/", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// probeWebsocket succeeds once a websocket RPC connection can be established.
+func (c *Checker) probeWebsocket(ctx context.Context, target Target) error {
+	conn, err := rpc.DialWebsocket(ctx, "ws://localhost:"+target.WebsocketPort, "http://localhost")
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	return nil
+}
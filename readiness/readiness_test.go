@@ -0,0 +1,95 @@
+package readiness
+
+import (
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/admin"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/net/context"
+)
+
+// AdminService stands in for the geth admin RPC API.
+type AdminService struct{}
+
+// NodeInfo answers admin_nodeInfo.
+func (a *AdminService) NodeInfo() map[string]string {
+	return map[string]string{"id": "test"}
+}
+
+func port(t *testing.T, server *httptest.Server) string {
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, p, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestGetChecker(t *testing.T) {
+	c := GetChecker(admin.GetClient())
+
+	var i interface{} = c
+	_, ok := i.(IChecker)
+
+	if !ok {
+		t.Error("GetChecker doesn't return an implementation of IChecker")
+	}
+}
+
+func TestChecker_WaitAll(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("admin", &AdminService{}); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	wsServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wsServer.Close()
+
+	target := Target{
+		Name:          "node_1",
+		AdminPort:     port(t, httpServer),
+		GatewayPort:   port(t, httpServer),
+		WebsocketPort: port(t, wsServer),
+	}
+
+	c := GetChecker(admin.GetClient())
+
+	err := c.WaitAll(context.Background(), []Target{target}, 5*time.Second, 10*time.Millisecond)
+	if err != nil {
+		t.Errorf("WaitAll should have succeeded against a live node: %s", err.Error())
+	}
+
+	wsServer.Close()
+	target.Name = "node_2"
+
+	err = c.WaitAll(context.Background(), []Target{target}, 200*time.Millisecond, 10*time.Millisecond)
+	if err == nil {
+		t.Fatal("WaitAll should have failed against a node with no websocket endpoint...")
+	}
+
+	readinessErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("WaitAll should return a *readiness.Error, got %T", err)
+	}
+	if len(readinessErr.Nodes) != 1 || readinessErr.Nodes[0].Ready {
+		t.Error("The readiness error should report node_2 as not ready")
+	}
+	if _, failed := readinessErr.Nodes[0].Failures[ProbeWebsocket]; !failed {
+		t.Error("The readiness error should report the websocket probe as failed")
+	}
+	if !strings.Contains(err.Error(), "node_2: not ready") {
+		t.Errorf("The readiness error should name the failed node: %s", err.Error())
+	}
+}
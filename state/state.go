@@ -0,0 +1,137 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/partition"
+	"github.com/MainframeHQ/swarmer/topology"
+	"github.com/go-errors/errors"
+)
+
+// DefaultDir is the directory, relative to the working directory, the state files are kept in.
+const DefaultDir = ".swarmer"
+
+// State records a cluster started by swarmer and the config it was started with.
+type State struct {
+	Cluster   string            `json:"cluster"`
+	Config    models.Config     `json:"config"`
+	StartedAt time.Time         `json:"started_at"`
+	Nodes     []models.NodeInfo `json:"nodes"`
+	Topology  []topology.Edge   `json:"topology"`
+	// Partition is the layout the nodes were last split into, empty when they are not.
+	Partition []partition.Group `json:"partition,omitempty"`
+}
+
+// IStore is the interface for keeping the state of clusters between runs.
+type IStore interface {
+	Load(cluster string) (State, bool, error)
+	Save(state State) error
+	Remove(cluster string) error
+}
+
+// Store is the struct for this implementation of IStore, keeping a state.json for every cluster
+// in a directory named after it.
+type Store struct {
+	dir string
+}
+
+// GetStore returns a pointer to a new instance of this implementation of IStore, keeping its
+// files in dir.
+func GetStore(dir string) *Store {
+	var s = Store{
+		dir: dir,
+	}
+
+	return &s
+}
+
+// Path returns the path of the state file of the cluster.
+func (s *Store) Path(cluster string) string {
+	return filepath.Join(s.dir, cluster, "state.json")
+}
+
+// Load reads the state of the cluster, returning false if there is none.
+func (s *Store) Load(cluster string) (State, bool, error) {
+	var state State
+
+	data, err := ioutil.ReadFile(s.Path(cluster))
+	if os.IsNotExist(err) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, err
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false, errors.Errorf("Error parsing state file %s: %s", s.Path(cluster), err.Error())
+	}
+
+	return state, true, nil
+}
+
+// Save writes the state of its cluster, replacing the file in one step so that readers never see
+// half of it.
+func (s *Store) Save(state State) error {
+	path := s.Path(state.Cluster)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "state")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Remove deletes the state of the cluster, if there is any.
+func (s *Store) Remove(cluster string) error {
+	err := os.Remove(s.Path(cluster))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// NodeHash returns a hash of the settings that define the container of the node at index and the
+// ID of the image it runs, so that a node started from the same image with the same settings can
+// be reused. The checkout of the node is left out, as the image stands for it, and so are the
+// topology and netem rules, which are changed on running nodes.
+func NodeHash(config models.Config, index int, imageID string) string {
+	node := config.Node(index)
+	node.Checkout = ""
+
+	data, _ := json.Marshal(struct {
+		Cluster string            `json:"cluster"`
+		Add     models.Mounts     `json:"add"`
+		Node    models.NodeConfig `json:"node"`
+		Image   string            `json:"image"`
+	}{config.Cluster, config.Add, node, imageID})
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/topology"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swarmer-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := GetStore(dir)
+
+	if _, ok, err := store.Load("ci"); ok || err != nil {
+		t.Errorf("Expected no state, got %v, %v", ok, err)
+	}
+
+	state := State{
+		Cluster:  "ci",
+		Config:   models.Config{Cluster: "ci", Nodes: models.Nodes{Count: 2}},
+		Nodes:    []models.NodeInfo{{ContainerID: "1", AdminPort: "32768"}, {ContainerID: "2"}},
+		Topology: []topology.Edge{{From: 0, To: 1}},
+	}
+	if err := store.Save(state); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, ok, err := store.Load("ci")
+	if err != nil || !ok {
+		t.Fatalf("Expected the saved state, got %v, %v", ok, err)
+	}
+	if !reflect.DeepEqual(loaded, state) {
+		t.Errorf("Expected %+v, got %+v", state, loaded)
+	}
+
+	if err := store.Remove("ci"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.Load("ci"); ok {
+		t.Error("The state should have been removed")
+	}
+	if err := store.Remove("ci"); err != nil {
+		t.Errorf("Removing a missing state should succeed, got %s", err.Error())
+	}
+}
+
+func TestNodeHash(t *testing.T) {
+	verbosity := 3
+	config := models.Config{
+		Cluster: "ci",
+		Repo:    "https://github.com/ethereum/go-ethereum",
+		Nodes:   models.Nodes{Count: 2, Overrides: []models.NodeConfig{{}, {Verbosity: &verbosity}}},
+	}
+
+	hash := NodeHash(config, 0, "sha256:1")
+
+	scaled := config
+	scaled.Nodes.Count = 5
+	if NodeHash(scaled, 0, "sha256:1") != hash {
+		t.Error("The node count shouldn't change the hash of a node")
+	}
+
+	if NodeHash(config, 1, "sha256:1") == hash {
+		t.Error("A node with other settings should have another hash")
+	}
+	if NodeHash(config, 0, "sha256:2") == hash {
+		t.Error("Another image should change the hash")
+	}
+
+	recreate := config
+	recreate.Recreate = true
+	if NodeHash(recreate, 0, "sha256:1") != hash {
+		t.Error("Asking to recreate shouldn't change the hash")
+	}
+
+	peered := config
+	peered.Topology = models.Topology{Type: "mesh"}
+	peered.Netem = []models.Netem{{Delay: 100 * time.Millisecond}}
+	if NodeHash(peered, 0, "sha256:1") != hash {
+		t.Error("The topology and netem rules shouldn't change the hash")
+	}
+
+	added := config
+	added.Add = models.Mounts{{Source: "data"}}
+	if NodeHash(added, 0, "sha256:1") == hash {
+		t.Error("A directory added to the nodes should change the hash")
+	}
+}
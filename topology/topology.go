@@ -0,0 +1,194 @@
+package topology
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/go-errors/errors"
+)
+
+// Supported topology types.
+const (
+	Ring     = "ring"
+	Mesh     = "mesh"
+	Star     = "star"
+	Line     = "line"
+	Random   = "random"
+	Explicit = "explicit"
+)
+
+// randomAttempts is how many times a random k-regular graph is regenerated before giving up.
+const randomAttempts = 1000
+
+// Edge is a single admin_addPeer call from node From to node To, both given by node index.
+type Edge struct {
+	From int `json:"from" yaml:"from"`
+	To   int `json:"to" yaml:"to"`
+}
+
+// Edges returns the peering calls needed to connect n nodes in the given topology. Peer
+// connections are bidirectional, so each pair of nodes appears at most once.
+func Edges(t models.Topology, n int) ([]Edge, error) {
+	if n < 2 {
+		return nil, nil
+	}
+
+	switch t.Type {
+	case "", Ring:
+		var edges []Edge
+		for i := 0; i < n; i++ {
+			edges = append(edges, Edge{From: i, To: (i + 1) % n})
+		}
+		return dedupe(edges), nil
+
+	case Mesh:
+		var edges []Edge
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				edges = append(edges, Edge{From: i, To: j})
+			}
+		}
+		return edges, nil
+
+	case Star:
+		if t.Hub < 0 || t.Hub >= n {
+			return nil, errors.Errorf("star topology hub %d is not one of the %d nodes", t.Hub, n)
+		}
+		var edges []Edge
+		for i := 0; i < n; i++ {
+			if i != t.Hub {
+				edges = append(edges, Edge{From: i, To: t.Hub})
+			}
+		}
+		return edges, nil
+
+	case Line:
+		var edges []Edge
+		for i := 0; i < n-1; i++ {
+			edges = append(edges, Edge{From: i, To: i + 1})
+		}
+		return edges, nil
+
+	case Random:
+		return randomRegular(n, t.Degree, t.Seed)
+
+	case Explicit:
+		var edges []Edge
+		for from, peers := range t.Peers {
+			for _, to := range peers {
+				if from < 0 || from >= n || to < 0 || to >= n {
+					return nil, errors.Errorf("explicit topology peer %d -> %d is not between two of the %d nodes", from, to, n)
+				}
+				if from == to {
+					return nil, errors.Errorf("explicit topology peers node %d with itself", from)
+				}
+				edges = append(edges, Edge{From: from, To: to})
+			}
+		}
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].From != edges[j].From {
+				return edges[i].From < edges[j].From
+			}
+			return edges[i].To < edges[j].To
+		})
+		return dedupe(edges), nil
+	}
+
+	return nil, errors.Errorf("unknown topology %q, expected one of %s, %s, %s, %s, %s or %s", t.Type, Ring, Mesh, Star, Line, Random, Explicit)
+}
+
+// randomRegular builds a random graph on n nodes where every node has exactly k peers, using the
+// pairing model and retrying whenever it produces a self loop or a duplicate edge.
+func randomRegular(n int, k int, seed int64) ([]Edge, error) {
+	if k < 1 || k >= n {
+		return nil, errors.Errorf("random topology degree must be between 1 and %d for %d nodes, got %d", n-1, n, k)
+	}
+	if n*k%2 != 0 {
+		return nil, errors.Errorf("random topology needs an even number of node*degree endpoints, got %d nodes of degree %d", n, k)
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+
+	for attempt := 0; attempt < randomAttempts; attempt++ {
+		var points []int
+		for i := 0; i < n; i++ {
+			for j := 0; j < k; j++ {
+				points = append(points, i)
+			}
+		}
+		rnd.Shuffle(len(points), func(i, j int) { points[i], points[j] = points[j], points[i] })
+
+		seen := map[Edge]bool{}
+		var edges []Edge
+		ok := true
+		for i := 0; i < len(points); i += 2 {
+			a, b := points[i], points[i+1]
+			if a > b {
+				a, b = b, a
+			}
+			edge := Edge{From: a, To: b}
+			if a == b || seen[edge] {
+				ok = false
+				break
+			}
+			seen[edge] = true
+			edges = append(edges, edge)
+		}
+
+		if ok {
+			return edges, nil
+		}
+	}
+
+	return nil, errors.Errorf("unable to generate a random %d-regular topology for %d nodes with seed %d", k, n, seed)
+}
+
+// dedupe drops edges connecting a pair of nodes that an earlier edge already connects.
+func dedupe(edges []Edge) []Edge {
+	seen := map[Edge]bool{}
+	var result []Edge
+	for _, edge := range edges {
+		key := edge.pair()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, edge)
+	}
+
+	return result
+}
+
+// Diff returns the edges of to connecting a pair of nodes that no edge of from connects, and the
+// edges of from connecting a pair that no edge of to connects. Applying both moves a cluster
+// peered as from to being peered as to.
+func Diff(from []Edge, to []Edge) ([]Edge, []Edge) {
+	return missing(to, from), missing(from, to)
+}
+
+// missing returns the edges of a connecting a pair of nodes that no edge of b connects.
+func missing(a []Edge, b []Edge) []Edge {
+	present := map[Edge]bool{}
+	for _, edge := range b {
+		present[edge.pair()] = true
+	}
+
+	var result []Edge
+	for _, edge := range a {
+		if !present[edge.pair()] {
+			result = append(result, edge)
+		}
+	}
+
+	return result
+}
+
+// pair returns the edge with the lower node index first, so both directions compare equal.
+func (e Edge) pair() Edge {
+	if e.From > e.To {
+		return Edge{From: e.To, To: e.From}
+	}
+
+	return e
+}
@@ -0,0 +1,112 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"gopkg.in/yaml.v2"
+)
+
+func TestEdges(t *testing.T) {
+	tests := []struct {
+		name     string
+		topology models.Topology
+		nodes    int
+		expected []Edge
+	}{
+		{"single node", models.Topology{Type: Mesh}, 1, nil},
+		{"default ring", models.Topology{}, 3, []Edge{{0, 1}, {1, 2}, {2, 0}}},
+		{"ring of two", models.Topology{Type: Ring}, 2, []Edge{{0, 1}}},
+		{"mesh", models.Topology{Type: Mesh}, 3, []Edge{{0, 1}, {0, 2}, {1, 2}}},
+		{"star", models.Topology{Type: Star, Hub: 1}, 3, []Edge{{0, 1}, {2, 1}}},
+		{"line", models.Topology{Type: Line}, 3, []Edge{{0, 1}, {1, 2}}},
+		{"explicit", models.Topology{Type: Explicit, Peers: map[int][]int{2: {0}, 0: {1, 2}}}, 3, []Edge{{0, 1}, {0, 2}}},
+	}
+
+	for _, test := range tests {
+		edges, err := Edges(test.topology, test.nodes)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(edges, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, edges)
+		}
+	}
+}
+
+func TestEdges_Random(t *testing.T) {
+	topology := models.Topology{Type: Random, Degree: 3, Seed: 42}
+
+	edges, err := Edges(topology, 8)
+	if err != nil {
+		t.Fatalf("Error generating random topology %s", err.Error())
+	}
+
+	degrees := map[int]int{}
+	for _, edge := range edges {
+		degrees[edge.From]++
+		degrees[edge.To]++
+	}
+	for i := 0; i < 8; i++ {
+		if degrees[i] != 3 {
+			t.Errorf("Node %d should have 3 peers, has %d", i, degrees[i])
+		}
+	}
+
+	again, _ := Edges(topology, 8)
+	if !reflect.DeepEqual(edges, again) {
+		t.Error("The same seed should produce the same random topology")
+	}
+}
+
+func TestEdges_Invalid(t *testing.T) {
+	invalid := []models.Topology{
+		{Type: "torus"},
+		{Type: Star, Hub: 5},
+		{Type: Random, Degree: 3},
+		{Type: Random, Degree: 5},
+		{Type: Explicit, Peers: map[int][]int{0: {7}}},
+		{Type: Explicit, Peers: map[int][]int{1: {1}}},
+	}
+
+	for _, topology := range invalid {
+		if _, err := Edges(topology, 5); err == nil {
+			t.Errorf("Topology %+v should have thrown an error for 5 nodes...", topology)
+		}
+	}
+}
+
+func TestTopology_UnmarshalYAML(t *testing.T) {
+	var config models.Config
+
+	err := yaml.Unmarshal([]byte("topology: mesh"), &config)
+	if err != nil || config.Topology.Type != Mesh {
+		t.Errorf("Expected a mesh topology from a plain string, got %+v (%v)", config.Topology, err)
+	}
+
+	err = yaml.Unmarshal([]byte("topology:\n  type: explicit\n  peers:\n    0: [1, 2]\n"), &config)
+	if err != nil || config.Topology.Type != Explicit || !reflect.DeepEqual(config.Topology.Peers[0], []int{1, 2}) {
+		t.Errorf("Expected an explicit topology from a mapping, got %+v (%v)", config.Topology, err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	ring3, _ := Edges(models.Topology{Type: Ring}, 3)
+	ring4, _ := Edges(models.Topology{Type: Ring}, 4)
+
+	added, removed := Diff(ring3, ring4)
+
+	if !reflect.DeepEqual(added, []Edge{{2, 3}, {3, 0}}) {
+		t.Errorf("Expected edges {2 3} and {3 0} to be added, got %v", added)
+	}
+	if !reflect.DeepEqual(removed, []Edge{{2, 0}}) {
+		t.Errorf("Expected edge {2 0} to be removed, got %v", removed)
+	}
+
+	added, removed = Diff([]Edge{{0, 1}}, []Edge{{1, 0}})
+	if added != nil || removed != nil {
+		t.Errorf("Edges in opposite directions should be equal, got %v added and %v removed", added, removed)
+	}
+}
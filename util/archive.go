@@ -0,0 +1,210 @@
+package util
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ContextDir is a directory to add to a Docker build context.
+type ContextDir struct {
+	// Dir is the directory on the host.
+	Dir string
+	// Prefix is the directory of the archive the contents of Dir are put in, empty meaning the
+	// root.
+	Prefix string
+	// Exclude are path.Match patterns of the paths, relative to Dir, to leave out along with
+	// everything below them. Patterns without a slash are also matched against the name of every
+	// file and directory, e.g. ".git" leaves out every .git directory.
+	Exclude []string
+}
+
+// ContextFile is a single file to add to a Docker build context.
+type ContextFile struct {
+	// Path is the file on the host.
+	Path string
+	// Name is the path of the file in the archive.
+	Name string
+}
+
+// TarDirectory takes a string path to a directory and returns its contents as a tar archive,
+// suitable for use as a Docker build context.
+func TarDirectory(dir string) (io.Reader, error) {
+	return TarDirectories(ContextDir{Dir: dir})
+}
+
+// TarDirectories returns the contents of the directories as a single tar archive, suitable for
+// use as a Docker build context. The archive is written as it is read, so that large directories
+// aren't held in memory, and an error reading a directory is returned by the reader. Closing the
+// reader stops the writing.
+func TarDirectories(dirs ...ContextDir) (io.Reader, error) {
+	return TarContext(dirs, nil)
+}
+
+// TarContext returns the contents of the directories, followed by the files, as a single tar
+// archive in the same way as TarDirectories.
+func TarContext(dirs []ContextDir, files []ContextFile) (io.Reader, error) {
+	for _, d := range dirs {
+		if _, err := os.Stat(d.Dir); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+
+		add := func(name string, file string, info os.FileInfo, link string) error {
+			header, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			header.Name = name
+
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			return copyFile(tw, file)
+		}
+
+		for _, d := range dirs {
+			err := walkContext(d, func(rel string, file string, info os.FileInfo, link string) error {
+				return add(path.Join(d.Prefix, rel), file, info, link)
+			})
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		for _, f := range files {
+			// symlinks are followed, so the file itself ends up in the archive
+			info, err := os.Stat(f.Path)
+			if err == nil {
+				err = add(f.Name, f.Path, info, "")
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		pw.CloseWithError(tw.Close())
+	}()
+
+	return pr, nil
+}
+
+// HashDirectory returns a hash of the names, modes and contents of everything in the directory
+// that a build context of it would hold, so that a changed tree can be told apart from one that
+// was built before.
+func HashDirectory(d ContextDir) (string, error) {
+	hash := sha256.New()
+
+	err := walkContext(d, func(rel string, file string, info os.FileInfo, link string) error {
+		fmt.Fprintf(hash, "%s\x00%o\x00%s\x00", rel, info.Mode(), link)
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return copyFile(hash, file)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HashFiles returns a hash of the contents of the files.
+func HashFiles(files ...string) (string, error) {
+	hash := sha256.New()
+
+	for _, file := range files {
+		if err := copyFile(hash, file); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// walkContext calls fn, in lexical order, for every path below the directory that isn't
+// excluded, with the path relative to the directory using forward slashes, the path on the host,
+// its info and the target of a symlink.
+func walkContext(d ContextDir, fn func(rel string, file string, info os.FileInfo, link string) error) error {
+	return filepath.Walk(d.Dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(d.Dir, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if excluded(rel, d.Exclude) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(file)
+			if err != nil {
+				return err
+			}
+		}
+
+		return fn(rel, file, info, link)
+	})
+}
+
+// excluded returns whether any of the patterns matches the slash separated path, or its name
+// for patterns without a slash.
+func excluded(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok && !strings.Contains(pattern, "/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyFile(w io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
@@ -0,0 +1,129 @@
+package util
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTarDirectory(t *testing.T) {
+	archive, err := TarDirectory("../docker")
+	if err != nil {
+		t.Fatalf("Error creating tar archive %s", err.Error())
+	}
+
+	names := map[string]bool{}
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error reading tar archive %s", err.Error())
+		}
+		names[header.Name] = true
+	}
+
+	for _, name := range []string{"Dockerfile", "start.sh"} {
+		if !names[name] {
+			t.Errorf("Tar archive should contain %s", name)
+		}
+	}
+
+	_, err = TarDirectory("non existent directory")
+	if err == nil {
+		t.Error("Trying to archive a missing directory should have thrown an error...")
+	}
+}
+
+func TestTarDirectories(t *testing.T) {
+	dir := sourceTree(t)
+	defer os.RemoveAll(dir)
+
+	archive, err := TarDirectories(
+		ContextDir{Dir: "../docker"},
+		ContextDir{Dir: dir, Prefix: "src", Exclude: []string{".git", "build/bin"}},
+	)
+	if err != nil {
+		t.Fatalf("Error creating tar archive %s", err.Error())
+	}
+
+	names := map[string]bool{}
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error reading tar archive %s", err.Error())
+		}
+		names[header.Name] = true
+	}
+
+	for _, name := range []string{"Dockerfile", "src/main.go", "src/build/ci.go"} {
+		if !names[name] {
+			t.Errorf("Tar archive should contain %s", name)
+		}
+	}
+	for _, name := range []string{"src/.git/HEAD", "src/vendor/lib/.git/HEAD", "src/build/bin/geth"} {
+		if names[name] {
+			t.Errorf("Tar archive shouldn't contain %s", name)
+		}
+	}
+}
+
+func TestHashDirectory(t *testing.T) {
+	dir := sourceTree(t)
+	defer os.RemoveAll(dir)
+
+	source := ContextDir{Dir: dir, Exclude: []string{".git", "build/bin"}}
+
+	hash, err := HashDirectory(source)
+	if err != nil {
+		t.Fatalf("Error hashing directory %s", err.Error())
+	}
+
+	write(t, filepath.Join(dir, "build", "bin", "geth"), "rebuilt")
+	if again, _ := HashDirectory(source); again != hash {
+		t.Error("Changing an excluded file shouldn't change the hash")
+	}
+
+	write(t, filepath.Join(dir, "main.go"), "package main // changed")
+	if changed, _ := HashDirectory(source); changed == hash {
+		t.Error("Changing a source file should change the hash")
+	}
+}
+
+// sourceTree creates a directory laid out like a go-ethereum checkout that has been built.
+func sourceTree(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "swarmer-src")
+	if err != nil {
+		t.Fatalf("Error creating directory %s", err.Error())
+	}
+
+	for name, content := range map[string]string{
+		"main.go":              "package main",
+		"build/ci.go":          "package main",
+		"build/bin/geth":       "binary",
+		".git/HEAD":            "ref: refs/heads/master",
+		"vendor/lib/.git/HEAD": "ref: refs/heads/master",
+	} {
+		write(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+
+	return dir
+}
+
+func write(t *testing.T, file string, content string) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("Error creating directory %s", err.Error())
+	}
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Error writing %s: %s", file, err.Error())
+	}
+}
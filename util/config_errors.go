@@ -0,0 +1,229 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// listIndex matches the index of a list entry in a key such as nodes[1].checkout.
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// ConfigProblem is a single invalid or unknown config value.
+type ConfigProblem struct {
+	// Source is where the value came from, a config file path or a flag or environment label.
+	Source string
+	// Line is the line of Source holding the value, or 0 when not known.
+	Line    int
+	Key     string
+	Message string
+}
+
+func (p ConfigProblem) String() string {
+	location := p.Source
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", p.Source, p.Line)
+	}
+
+	if p.Key == "" {
+		return fmt.Sprintf("%s: %s", location, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", location, p.Key, p.Message)
+}
+
+// ConfigError is returned when a config has one or more problems.
+type ConfigError struct {
+	Problems []ConfigProblem
+}
+
+func (e *ConfigError) Error() string {
+	var lines []string
+	for _, problem := range e.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+// NewConfigProblem returns a problem with the given key, locating the line it was set on when
+// source is a config file.
+func NewConfigProblem(source string, key string, message string) ConfigProblem {
+	return ConfigProblem{
+		Source:  source,
+		Line:    LocateConfigKey(source, key),
+		Key:     key,
+		Message: message,
+	}
+}
+
+// LocateConfigKey returns the line of the config file at source where key is set, or 0 if
+// source is not a config file or the key can't be found.
+func LocateConfigKey(source string, key string) int {
+	if source == "" || source == SourceDefault || strings.HasPrefix(source, "env ") || strings.HasPrefix(source, "flag ") {
+		return 0
+	}
+
+	data, err := ioutil.ReadFile(source)
+	if err != nil {
+		return 0
+	}
+
+	return KeyLine(DetectConfigFormat(source, data), data, key)
+}
+
+// KeyLine returns the line where the dotted key is set in a config file of the given format, or
+// 0 if it can't be found. Each part of the key is looked for after the line of the part before,
+// and when only the first parts are found, such as for the shorthand `nodes: 3` of nodes.count,
+// the line of the last one found is returned. List indexes like nodes[1] are ignored.
+func KeyLine(format string, data []byte, key string) int {
+	lines := strings.Split(string(data), "\n")
+	parts := strings.Split(listIndex.ReplaceAllString(key, ""), ".")
+	start := 0
+	line := -1
+
+	if format == FormatToml && len(parts) > 1 {
+		for i := len(parts) - 1; i > 0; i-- {
+			header := "[" + strings.Join(parts[:i+1], ".") + "]"
+			if found := findLine(lines, 0, regexp.MustCompile(`^\s*`+regexp.QuoteMeta(header))); found >= 0 {
+				line = found
+				start = line + 1
+				parts = parts[i+1:]
+				break
+			}
+		}
+	}
+
+	for _, part := range parts {
+		quoted := regexp.QuoteMeta(part)
+
+		var pattern *regexp.Regexp
+		switch format {
+		case FormatToml:
+			pattern = regexp.MustCompile(`^\s*(\[{1,2}\s*` + quoted + `\s*\]|["']?` + quoted + `["']?\s*=)`)
+		case FormatJSON:
+			pattern = regexp.MustCompile(`"` + quoted + `"\s*:`)
+		default:
+			pattern = regexp.MustCompile(`^\s*(-\s+)?["']?` + quoted + `["']?\s*:`)
+		}
+
+		found := findLine(lines, start, pattern)
+		if found < 0 {
+			break
+		}
+		line = found
+		start = line + 1
+	}
+
+	return line + 1
+}
+
+func findLine(lines []string, start int, pattern *regexp.Regexp) int {
+	for i := start; i < len(lines); i++ {
+		if pattern.MatchString(lines[i]) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unknownKeys returns the dotted paths of every key in a decoded config document that doesn't
+// match a config value, sorted.
+func unknownKeys(doc map[interface{}]interface{}, prefix string) []string {
+	var unknown []string
+
+	for k, value := range doc {
+		key := prefix + fmt.Sprint(k)
+
+		if _, ok := configFields[key]; ok {
+			if list, ok := value.([]interface{}); ok {
+				unknown = append(unknown, unknownListKeys(list, key)...)
+			}
+			continue
+		}
+
+		if !isConfigStruct(key) {
+			unknown = append(unknown, key)
+			continue
+		}
+
+		switch nested := value.(type) {
+		case map[interface{}]interface{}:
+			unknown = append(unknown, unknownKeys(nested, key+".")...)
+		case []interface{}:
+			unknown = append(unknown, unknownListKeys(nested, key)...)
+		}
+	}
+
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+// unknownListKeys returns the keys of the entries of a list of config structs, such as the node
+// overrides, that don't match a field of the struct. They are named by the list key and the
+// index of the entry, e.g. nodes[1].chekout. key is either the list itself or the struct it is
+// the shorthand for.
+func unknownListKeys(list []interface{}, key string) []string {
+	known := listEntryKeys(key)
+	if known == nil {
+		return nil
+	}
+
+	var unknown []string
+	for i, entry := range list {
+		fields, ok := entry.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		for k := range fields {
+			if !known[fmt.Sprint(k)] {
+				unknown = append(unknown, fmt.Sprintf("%s[%d].%v", key, i, k))
+			}
+		}
+	}
+
+	return unknown
+}
+
+// listEntryKeys returns the yaml keys of the struct held in the list at key, or in the first
+// list of the struct at key. It returns nil if there is no such list.
+func listEntryKeys(key string) map[string]bool {
+	for _, known := range configKeys {
+		if known != key && !strings.HasPrefix(known, key+".") {
+			continue
+		}
+
+		t := configType.FieldByIndex(configFields[known]).Type
+		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		keys := map[string]bool{}
+		for i := 0; i < t.Elem().NumField(); i++ {
+			name := strings.Split(t.Elem().Field(i).Tag.Get("yaml"), ",")[0]
+			if name != "" && name != "-" {
+				keys[name] = true
+			}
+		}
+		return keys
+	}
+
+	return nil
+}
+
+// isConfigStruct reports whether key names a struct of config values, such as topology.
+func isConfigStruct(key string) bool {
+	for _, known := range configKeys {
+		if strings.HasPrefix(known, key+".") {
+			return true
+		}
+	}
+
+	return false
+}
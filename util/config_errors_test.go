@@ -0,0 +1,99 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigParser_UnknownKeys(t *testing.T) {
+	parser := GetConfigParser()
+
+	tests := map[string][]ConfigProblem{
+		"testdata/unknown.yml": {
+			{Source: "testdata/unknown.yml", Line: 2, Key: "node", Message: "unknown key"},
+			{Source: "testdata/unknown.yml", Line: 5, Key: "topology.hubb", Message: "unknown key"},
+		},
+		"testdata/unknown.toml": {
+			{Source: "testdata/unknown.toml", Line: 2, Key: "node", Message: "unknown key"},
+			{Source: "testdata/unknown.toml", Line: 6, Key: "topology.hubb", Message: "unknown key"},
+		},
+		"testdata/unknown_order.yml": {
+			{Source: "testdata/unknown_order.yml", Line: 1, Key: "node", Message: "unknown key"},
+			{Source: "testdata/unknown_order.yml", Line: 2, Key: "ens_api", Message: "unknown key"},
+		},
+		"testdata/unknown.json": {
+			{Source: "testdata/unknown.json", Line: 3, Key: "node", Message: "unknown key"},
+			{Source: "testdata/unknown.json", Line: 6, Key: "topology.hubb", Message: "unknown key"},
+		},
+	}
+
+	for path, expected := range tests {
+		_, err := parser.ParseConfig(path)
+
+		configErr, ok := err.(*ConfigError)
+		if !ok {
+			t.Errorf("%s: expected a *ConfigError, got %v", path, err)
+			continue
+		}
+		if len(configErr.Problems) != len(expected) {
+			t.Errorf("%s: expected %v, got %v", path, expected, configErr.Problems)
+			continue
+		}
+		for i := range expected {
+			if configErr.Problems[i] != expected[i] {
+				t.Errorf("%s: expected %v, got %v", path, expected[i], configErr.Problems[i])
+			}
+		}
+	}
+}
+
+func TestConfigParser_WrongType(t *testing.T) {
+	parser := GetConfigParser()
+
+	_, err := parser.ParseConfig("testdata/wrong_type.yml")
+
+	configErr, ok := err.(*ConfigError)
+	if !ok || len(configErr.Problems) != 1 {
+		t.Fatalf("Expected a *ConfigError with one problem, got %v", err)
+	}
+	if configErr.Problems[0].Line != 2 || !strings.Contains(configErr.Problems[0].Message, "three") {
+		t.Errorf("Expected the bad value on line 2 to be reported, got %v", configErr.Problems[0])
+	}
+}
+
+func TestKeyLine(t *testing.T) {
+	tests := []struct {
+		format   string
+		data     string
+		key      string
+		expected int
+	}{
+		{FormatYaml, "repo: x\nnodes: 1\n", "nodes", 2},
+		{FormatYaml, "repo: x\ntopology:\n  peers:\n    0: [1]\n", "topology.peers", 3},
+		{FormatYaml, "repo: x\n", "nodes", 0},
+		{FormatToml, "nodes = 1\n[topology]\ntype = \"star\"\n", "topology.type", 3},
+		{FormatToml, "nodes = 1\n[topology.peers]\n0 = [1]\n", "topology.peers", 2},
+		{FormatJSON, "{\n  \"nodes\": 1\n}", "nodes", 2},
+	}
+
+	for _, test := range tests {
+		if line := KeyLine(test.format, []byte(test.data), test.key); line != test.expected {
+			t.Errorf("Expected %s at line %d of %q, got %d", test.key, test.expected, test.data, line)
+		}
+	}
+}
+
+func TestConfigError_Error(t *testing.T) {
+	err := &ConfigError{Problems: []ConfigProblem{
+		{Source: "swarmer.yml", Line: 3, Key: "nodes", Message: "must be between 1 and 100, got 0"},
+		{Source: "flag --add", Key: "add", Message: "/missing does not exist"},
+	}}
+
+	expected := "invalid configuration:\n" +
+		"  swarmer.yml:3: nodes: must be between 1 and 100, got 0\n" +
+		"  flag --add: add: /missing does not exist"
+
+	if err.Error() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, err.Error())
+	}
+}
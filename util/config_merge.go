@@ -0,0 +1,144 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/MainframeHQ/swarmer/models"
+)
+
+// SourceDefault labels config values that nothing overrode.
+const SourceDefault = "default"
+
+// ConfigLayer holds the config values read from one source, along with a label of where each
+// value it sets came from, keyed by config key.
+type ConfigLayer struct {
+	Config  models.Config
+	Sources map[string]string
+}
+
+var configType = reflect.TypeOf(models.Config{})
+
+// configFields maps every config key to the index of its field in models.Config. Keys are the
+// yaml tags of the fields, joined with dots for nested structs such as topology.
+var configFields, configKeys = indexConfigFields(configType, "", nil)
+
+func indexConfigFields(t reflect.Type, prefix string, index []int) (map[string][]int, []string) {
+	fields := map[string][]int{}
+	var keys []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			nestedFields, nestedKeys := indexConfigFields(field.Type, prefix+name+".", fieldIndex)
+			for key, nestedIndex := range nestedFields {
+				fields[key] = nestedIndex
+			}
+			keys = append(keys, nestedKeys...)
+			continue
+		}
+
+		fields[prefix+name] = fieldIndex
+		keys = append(keys, prefix+name)
+	}
+
+	return fields, keys
+}
+
+// ConfigKeys returns the key of every config value, in the order the fields are declared.
+func ConfigKeys() []string {
+	return append([]string{}, configKeys...)
+}
+
+// ConfigKeyOf returns the key of the field of config that ptr points to, or an empty string if
+// ptr doesn't point into config.
+func ConfigKeyOf(config *models.Config, ptr interface{}) string {
+	target := reflect.ValueOf(ptr)
+	if target.Kind() != reflect.Ptr {
+		return ""
+	}
+
+	v := reflect.ValueOf(config).Elem()
+	for _, key := range configKeys {
+		field := v.FieldByIndex(configFields[key])
+		if field.Addr().Pointer() == target.Pointer() && field.Type() == target.Elem().Type() {
+			return key
+		}
+	}
+
+	return ""
+}
+
+// ConfigValue returns the value of the given key in config.
+func ConfigValue(config models.Config, key string) interface{} {
+	return reflect.ValueOf(config).FieldByIndex(configFields[key]).Interface()
+}
+
+// SetConfigValue sets the given key in config. It panics if value is not of the field's type.
+func SetConfigValue(config *models.Config, key string, value interface{}) {
+	reflect.ValueOf(config).Elem().FieldByIndex(configFields[key]).Set(reflect.ValueOf(value))
+}
+
+// MergeConfig applies the layers in order, each one overriding the values it sets. It returns
+// the merged config along with the source of every value.
+func MergeConfig(layers ...ConfigLayer) (models.Config, map[string]string) {
+	var merged models.Config
+	sources := map[string]string{}
+
+	for _, key := range configKeys {
+		sources[key] = SourceDefault
+	}
+
+	for _, layer := range layers {
+		for key, source := range layer.Sources {
+			if _, ok := configFields[key]; !ok {
+				continue
+			}
+			SetConfigValue(&merged, key, ConfigValue(layer.Config, key))
+			sources[key] = source
+		}
+	}
+
+	return merged, sources
+}
+
+// documentSets reports whether a decoded config document sets the given key.
+func documentSets(doc map[interface{}]interface{}, key string) bool {
+	parts := strings.Split(key, ".")
+
+	for i, part := range parts {
+		value, ok := doc[part]
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+
+		switch nested := value.(type) {
+		case map[interface{}]interface{}:
+			doc = nested
+		case []interface{}:
+			// a list in place of a struct, like the node overrides of `nodes`, sets all of it
+			return true
+		default:
+			// a scalar in place of a struct, like `topology: mesh` or `nodes: 3`, is shorthand for
+			// its first value
+			prefix := strings.Join(parts[:i+1], ".") + "."
+			for _, known := range configKeys {
+				if strings.HasPrefix(known, prefix) {
+					return known == key
+				}
+			}
+			return false
+		}
+	}
+
+	return false
+}
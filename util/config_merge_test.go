@@ -0,0 +1,113 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/MainframeHQ/swarmer/models"
+)
+
+func TestConfigKeys(t *testing.T) {
+	keys := map[string]bool{}
+	for _, key := range ConfigKeys() {
+		keys[key] = true
+	}
+
+	for _, key := range []string{"nodes.count", "nodes.overrides", "ens-api", "ready_timeout", "topology.type", "topology.peers"} {
+		if !keys[key] {
+			t.Errorf("Config keys should contain %s", key)
+		}
+	}
+	if keys["topology"] || keys["nodes"] {
+		t.Error("Config keys should contain the fields of topology and nodes rather than the structs themselves")
+	}
+}
+
+func TestConfigKeyOf(t *testing.T) {
+	var config models.Config
+
+	if key := ConfigKeyOf(&config, &config.Nodes.Count); key != "nodes.count" {
+		t.Errorf("Expected nodes.count, got %q", key)
+	}
+	if key := ConfigKeyOf(&config, &config.Topology.Hub); key != "topology.hub" {
+		t.Errorf("Expected topology.hub, got %q", key)
+	}
+
+	var other models.Config
+	if key := ConfigKeyOf(&config, &other.Nodes.Count); key != "" {
+		t.Errorf("A pointer outside the config should have no key, got %q", key)
+	}
+}
+
+func TestMergeConfig(t *testing.T) {
+	defaults := ConfigLayer{
+		Config:  models.Config{Nodes: models.Nodes{Count: 1}, Cluster: "swarmer", Topology: models.Topology{Degree: 2}},
+		Sources: map[string]string{"nodes.count": SourceDefault, "cluster": SourceDefault, "topology.degree": SourceDefault},
+	}
+	file := ConfigLayer{
+		Config:  models.Config{Nodes: models.Nodes{Count: 3}, Repo: "https://example.com/repo", Topology: models.Topology{Type: "random"}},
+		Sources: map[string]string{"nodes.count": "swarmer.yml", "repo": "swarmer.yml", "topology.type": "swarmer.yml"},
+	}
+	flags := ConfigLayer{
+		Config:  models.Config{Nodes: models.Nodes{Count: 5}},
+		Sources: map[string]string{"nodes.count": "flag --nodes"},
+	}
+
+	config, sources := MergeConfig(defaults, file, flags)
+
+	if config.Nodes.Count != 5 || sources["nodes.count"] != "flag --nodes" {
+		t.Errorf("Flags should override the config file, got %d from %s", config.Nodes.Count, sources["nodes.count"])
+	}
+	if config.Repo != "https://example.com/repo" || sources["repo"] != "swarmer.yml" {
+		t.Errorf("The config file should override defaults, got %s from %s", config.Repo, sources["repo"])
+	}
+	if config.Cluster != "swarmer" || config.Topology.Degree != 2 || config.Topology.Type != "random" {
+		t.Errorf("Values not set by later layers should be kept, got %+v", config)
+	}
+	if sources["checkout"] != SourceDefault {
+		t.Errorf("Values set by no layer should be labelled %s, got %s", SourceDefault, sources["checkout"])
+	}
+}
+
+func TestConfigParser_ParseConfigLayer(t *testing.T) {
+	parser := GetConfigParser()
+
+	for _, path := range []string{"testdata/swarmer.yml", "testdata/swarmer.toml", "testdata/swarmer.json"} {
+		layer, err := parser.ParseConfigLayer(path)
+		if err != nil {
+			t.Errorf("Error parsing %s %s", path, err.Error())
+			continue
+		}
+
+		for _, key := range []string{"repo", "nodes.count", "ready_timeout", "topology.type", "topology.peers"} {
+			if layer.Sources[key] != path {
+				t.Errorf("%s: %s should be labelled with the file path, got %q", path, key, layer.Sources[key])
+			}
+		}
+		for _, key := range []string{"cluster", "topology.hub"} {
+			if _, ok := layer.Sources[key]; ok {
+				t.Errorf("%s: %s is not in the file and shouldn't have a source", path, key)
+			}
+		}
+	}
+
+	if _, err := parser.ParseConfigLayer("non existent file"); err == nil {
+		t.Error("Trying to parse a missing config file should have thrown an error...")
+	}
+}
+
+func TestDocumentSets(t *testing.T) {
+	doc := map[interface{}]interface{}{"nodes": 1, "topology": "mesh"}
+
+	if !documentSets(doc, "nodes.count") || !documentSets(doc, "topology.type") {
+		t.Error("The nodes and topology shorthands should be set")
+	}
+	if documentSets(doc, "nodes.overrides") || documentSets(doc, "topology.hub") || documentSets(doc, "repo") {
+		t.Error("nodes.overrides, topology.hub and repo should not be set")
+	}
+
+	doc = map[interface{}]interface{}{"nodes": []interface{}{map[interface{}]interface{}{"checkout": "v1"}}}
+
+	if !documentSets(doc, "nodes.count") || !documentSets(doc, "nodes.overrides") {
+		t.Error("A list of nodes should set both the count and the overrides")
+	}
+}
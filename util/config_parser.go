@@ -1,14 +1,44 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
 	"github.com/MainframeHQ/swarmer/models"
 	"gopkg.in/yaml.v2"
-	"io/ioutil"
 )
 
+// Config file formats understood by ParseConfig.
+const (
+	FormatYaml = "yaml"
+	FormatToml = "toml"
+	FormatJSON = "json"
+)
+
+// DefaultConfigFiles are the config files looked for in the working directory, in order, when
+// no config file is given.
+var DefaultConfigFiles = []string{"swarmer.yml", "swarmer.yaml", "swarmer.toml", "swarmer.json"}
+
+// yamlErrorLine splits the line number from the messages of yaml type errors.
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// tomlLine matches a TOML table header or key/value pair, neither of which is valid YAML.
+var tomlLine = regexp.MustCompile(`(?m)^\s*(\[[^\]]+\]|[\w.-]+\s*=)`)
+
 // IConfigParser is the interface to use for parsing file configurations.
 type IConfigParser interface {
+	ParseConfig(path string) (models.Config, error)
 	ParseYamlConfig(path string) (models.Config, error)
+	ParseTomlConfig(path string) (models.Config, error)
+	ParseJSONConfig(path string) (models.Config, error)
+	ParseConfigLayer(path string) (ConfigLayer, error)
 }
 
 // ConfigParser is the struct for this implementation of IConfigParser.
@@ -22,16 +52,198 @@ func GetConfigParser() *ConfigParser {
 	return &c
 }
 
+// ParseConfig takes a string path to a YAML, TOML or JSON config file and returns a config
+// model. The format is taken from the file extension, or detected from the content when the
+// extension is not recognised. Unknown keys and values of the wrong type are returned as a
+// *ConfigError.
+func (c *ConfigParser) ParseConfig(path string) (models.Config, error) {
+	config, _, err := c.parse(path, "")
+
+	return config, err
+}
+
 // ParseYamlConfig takes a string path to the yaml config file and returns a config model.
 func (c *ConfigParser) ParseYamlConfig(path string) (models.Config, error) {
+	config, _, err := c.parse(path, FormatYaml)
+
+	return config, err
+}
+
+// ParseTomlConfig takes a string path to the toml config file and returns a config model.
+func (c *ConfigParser) ParseTomlConfig(path string) (models.Config, error) {
+	config, _, err := c.parse(path, FormatToml)
+
+	return config, err
+}
+
+// ParseJSONConfig takes a string path to the json config file and returns a config model.
+func (c *ConfigParser) ParseJSONConfig(path string) (models.Config, error) {
+	config, _, err := c.parse(path, FormatJSON)
+
+	return config, err
+}
+
+// ParseConfigLayer takes a string path to a YAML, TOML or JSON config file and returns its
+// values as a config layer, labelling every key the file sets with its path.
+func (c *ConfigParser) ParseConfigLayer(path string) (ConfigLayer, error) {
+	layer := ConfigLayer{Sources: map[string]string{}}
+
+	config, doc, err := c.parse(path, "")
+	if err != nil {
+		return layer, err
+	}
+	layer.Config = config
+
+	for _, key := range configKeys {
+		if documentSets(doc, key) {
+			layer.Sources[key] = path
+		}
+	}
+
+	return layer, nil
+}
+
+// parse reads the config file at path in the given format, or the detected format if empty,
+// and returns both the config and the generic document it was decoded from.
+func (c *ConfigParser) parse(path string, format string) (models.Config, map[interface{}]interface{}, error) {
 	var config models.Config
 
-	yamlFile, err := ioutil.ReadFile(path)
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return config, err
+		return config, nil, err
 	}
 
-	err = yaml.Unmarshal(yamlFile, &config)
+	if format == "" {
+		format = DetectConfigFormat(path, data)
+	}
 
-	return config, err
+	doc, err := c.decodeDocument(format, data)
+	if err != nil {
+		return config, nil, err
+	}
+
+	var problems []ConfigProblem
+	for _, key := range unknownKeys(doc, "") {
+		problems = append(problems, ConfigProblem{
+			Source:  path,
+			Line:    KeyLine(format, data, key),
+			Key:     key,
+			Message: "unknown key",
+		})
+	}
+	if len(problems) > 0 {
+		sortByLine(problems)
+		return config, doc, &ConfigError{Problems: problems}
+	}
+
+	// YAML is decoded from the file itself so type errors carry the right line numbers
+	yamlData := data
+	if format != FormatYaml {
+		yamlData, err = yaml.Marshal(doc)
+		if err != nil {
+			return config, doc, err
+		}
+	}
+
+	err = yaml.Unmarshal(yamlData, &config)
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		for _, message := range typeErr.Errors {
+			problem := ConfigProblem{Source: path, Message: message}
+			if match := yamlErrorLine.FindStringSubmatch(message); match != nil {
+				problem.Message = match[2]
+				if format == FormatYaml {
+					problem.Line, _ = strconv.Atoi(match[1])
+				}
+			}
+			problems = append(problems, problem)
+		}
+		return config, doc, &ConfigError{Problems: problems}
+	}
+
+	return config, doc, err
+}
+
+// sortByLine orders problems by the line they are on, keeping the order of problems on the same
+// line and putting those without a line last.
+func sortByLine(problems []ConfigProblem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i].Line, problems[j].Line
+		return a > 0 && (b == 0 || a < b)
+	})
+}
+
+// DetectConfigFormat returns the format of a config file from its extension, falling back to
+// sniffing its content.
+func DetectConfigFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return FormatYaml
+	case ".toml":
+		return FormatToml
+	case ".json":
+		return FormatJSON
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return FormatJSON
+	}
+	if tomlLine.Match(data) {
+		return FormatToml
+	}
+
+	return FormatYaml
+}
+
+// decodeDocument decodes a config file of the given format into generic YAML tables.
+func (c *ConfigParser) decodeDocument(format string, data []byte) (map[interface{}]interface{}, error) {
+	var doc map[string]interface{}
+
+	switch format {
+	case FormatToml:
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	default:
+		yamlDoc := map[interface{}]interface{}{}
+		err := yaml.Unmarshal(data, &yamlDoc)
+		return yamlDoc, err
+	}
+
+	return normalizeKeys(doc).(map[interface{}]interface{}), nil
+}
+
+// normalizeKeys rewrites decoded tables so their keys marshal to YAML as the type they look
+// like. TOML and JSON keys are always strings, but keys such as the node indexes in topology
+// peers are ints.
+func normalizeKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := map[interface{}]interface{}{}
+		for key, value := range v {
+			if i, err := strconv.Atoi(key); err == nil {
+				m[i] = normalizeKeys(value)
+			} else {
+				m[key] = normalizeKeys(value)
+			}
+		}
+		return m
+	case []map[string]interface{}:
+		var s []interface{}
+		for _, value := range v {
+			s = append(s, normalizeKeys(value))
+		}
+		return s
+	case []interface{}:
+		var s []interface{}
+		for _, value := range v {
+			s = append(s, normalizeKeys(value))
+		}
+		return s
+	}
+
+	return v
 }
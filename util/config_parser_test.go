@@ -1,6 +1,12 @@
 package util
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MainframeHQ/swarmer/models"
+)
 
 func TestGetConfigParser(t *testing.T) {
 	parser := GetConfigParser()
@@ -28,3 +34,171 @@ func TestConfigParser_ParseYamlConfig(t *testing.T) {
 		t.Error("Trying to parse a missing YAML file should have thrown an error...")
 	}
 }
+
+func TestConfigParser_ParseTomlConfig(t *testing.T) {
+	parser := GetConfigParser()
+
+	config, err := parser.ParseTomlConfig("testdata/swarmer.toml")
+	if err != nil {
+		t.Fatalf("Error parsing toml file %s", err.Error())
+	}
+	checkTestConfig(t, "toml", config)
+
+	_, err = parser.ParseTomlConfig("testdata/invalid.toml")
+	if err == nil {
+		t.Error("Trying to parse an invalid TOML file should have thrown an error...")
+	}
+
+	_, err = parser.ParseTomlConfig("non existent file")
+	if err == nil {
+		t.Error("Trying to parse a missing TOML file should have thrown an error...")
+	}
+}
+
+func TestConfigParser_ParseJSONConfig(t *testing.T) {
+	parser := GetConfigParser()
+
+	config, err := parser.ParseJSONConfig("testdata/swarmer.json")
+	if err != nil {
+		t.Fatalf("Error parsing json file %s", err.Error())
+	}
+	checkTestConfig(t, "json", config)
+
+	_, err = parser.ParseJSONConfig("testdata/swarmer.toml")
+	if err == nil {
+		t.Error("Trying to parse a TOML file as JSON should have thrown an error...")
+	}
+
+	_, err = parser.ParseJSONConfig("non existent file")
+	if err == nil {
+		t.Error("Trying to parse a missing JSON file should have thrown an error...")
+	}
+}
+
+func TestConfigParser_ParseConfig(t *testing.T) {
+	parser := GetConfigParser()
+
+	for _, path := range []string{"testdata/swarmer.yml", "testdata/swarmer.toml", "testdata/swarmer.json", "testdata/swarmer_toml.conf"} {
+		config, err := parser.ParseConfig(path)
+		if err != nil {
+			t.Errorf("Error parsing %s %s", path, err.Error())
+			continue
+		}
+		checkTestConfig(t, path, config)
+	}
+
+	_, err := parser.ParseConfig("non existent file")
+	if err == nil {
+		t.Error("Trying to parse a missing config file should have thrown an error...")
+	}
+}
+
+func TestDetectConfigFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		data     string
+		expected string
+	}{
+		{"swarmer.yml", "nodes = 1", FormatYaml},
+		{"swarmer.YAML", "", FormatYaml},
+		{"swarmer.toml", "", FormatToml},
+		{"swarmer.json", "", FormatJSON},
+		{"swarmer", "  {\"nodes\": 1}", FormatJSON},
+		{"swarmer", "# nodes\nnodes = 1", FormatToml},
+		{"swarmer", "[topology]\ntype = \"mesh\"", FormatToml},
+		{"swarmer", "nodes: 1\nens-api: http://example.com/?a=b", FormatYaml},
+	}
+
+	for _, test := range tests {
+		format := DetectConfigFormat(test.path, []byte(test.data))
+		if format != test.expected {
+			t.Errorf("Expected %s for %s %q, got %s", test.expected, test.path, test.data, format)
+		}
+	}
+}
+
+// checkTestConfig compares a config parsed from one of the testdata files, which all describe
+// the same cluster, with the expected values.
+func checkTestConfig(t *testing.T, name string, config models.Config) {
+	expected := models.Config{
+		Repo:         "https://github.com/ethereum/go-ethereum",
+		Checkout:     "master",
+		Nodes:        models.Nodes{Count: 3},
+		ENS:          "https://mainnet.infura.io/v3/<REPLACE-WITH-YOUR-INFURA-KEY>",
+		Geth:         true,
+		DockerLog:    "docker_log",
+		SwarmLog:     "swarm_log",
+		ReadyTimeout: 20 * time.Minute,
+		Topology: models.Topology{
+			Type:  "explicit",
+			Peers: map[int][]int{0: {1, 2}},
+		},
+	}
+
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("%s: expected %+v, got %+v", name, expected, config)
+	}
+}
+
+func TestConfigParser_ParseConfig_NodeOverrides(t *testing.T) {
+	parser := GetConfigParser()
+
+	verbosity := 3
+	geth := false
+	expected := models.Nodes{
+		Count: 3,
+		Overrides: []models.NodeConfig{
+			{},
+			{Checkout: "v1.8.17", Verbosity: &verbosity},
+			{Geth: &geth, ENS: "http://localhost:8545", SwarmFlags: []string{"--maxpeers", "5"}, CPUs: 0.5, Memory: "512m"},
+		},
+	}
+
+	for _, path := range []string{"testdata/nodes.yml", "testdata/nodes.toml"} {
+		layer, err := parser.ParseConfigLayer(path)
+		if err != nil {
+			t.Errorf("Error parsing %s %s", path, err.Error())
+			continue
+		}
+
+		if !reflect.DeepEqual(layer.Config.Nodes, expected) {
+			t.Errorf("%s: expected nodes %+v, got %+v", path, expected, layer.Config.Nodes)
+		}
+		if layer.Sources["nodes.count"] != path || layer.Sources["nodes.overrides"] != path {
+			t.Errorf("%s: the list of nodes should set both the count and the overrides", path)
+		}
+	}
+
+	_, err := parser.ParseConfig("testdata/unknown_node.yml")
+	configErr, ok := err.(*ConfigError)
+	if !ok || len(configErr.Problems) != 1 {
+		t.Fatalf("Expected a *ConfigError with one problem, got %v", err)
+	}
+	if problem := configErr.Problems[0]; problem.Key != "nodes[1].chekout" || problem.Line != 4 {
+		t.Errorf("Expected the unknown key of node 1 on line 4, got %v", problem)
+	}
+}
+
+func TestConfigParser_ParseConfig_Add(t *testing.T) {
+	parser := GetConfigParser()
+
+	tests := []struct {
+		path     string
+		expected models.Mounts
+	}{
+		{"testdata/add.yml", models.Mounts{{Source: "./data"}, {Source: "./out", Target: "/out", Mode: "rw"}, {Source: "./keys", Target: "/keys"}}},
+		{"testdata/add.toml", models.Mounts{{Source: "./data"}, {Source: "./out", Target: "/out", Mode: "rw"}}},
+	}
+
+	for _, test := range tests {
+		config, err := parser.ParseConfig(test.path)
+		if err != nil {
+			t.Errorf("Error parsing %s %s", test.path, err.Error())
+			continue
+		}
+
+		if !reflect.DeepEqual(config.Add, test.expected) {
+			t.Errorf("%s: expected mounts %+v, got %+v", test.path, test.expected, config.Add)
+		}
+	}
+}
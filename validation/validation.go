@@ -0,0 +1,338 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/netem"
+	"github.com/MainframeHQ/swarmer/orchestrator"
+	"github.com/MainframeHQ/swarmer/output"
+	"github.com/MainframeHQ/swarmer/topology"
+	"github.com/MainframeHQ/swarmer/util"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/go-units"
+	"github.com/go-errors/errors"
+)
+
+// MaxNodes is the largest cluster swarmer will start.
+const MaxNodes = 100
+
+// scpLike matches scp style Git URLs such as git@github.com:ethereum/go-ethereum.git.
+var scpLike = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/].*$`)
+
+// embeddedURL finds the URL in an ens-api value, which may be prefixed with a TLD and contract
+// address, e.g. test:0x0123...@http://localhost:8545.
+var embeddedURL = regexp.MustCompile(`[a-z][a-z0-9+.-]*://\S*$`)
+
+// Validate checks the config for problems before anything is started. sources gives where each
+// value came from, so that problems can point at the flag, environment variable or config file
+// line that set it. All problems found are returned together as a *util.ConfigError.
+func Validate(config models.Config, sources map[string]string) error {
+	var problems []util.ConfigProblem
+	problem := func(key string, format string, args ...interface{}) {
+		problems = append(problems, util.NewConfigProblem(sources[key], key, fmt.Sprintf(format, args...)))
+	}
+
+	count := config.Nodes.Count
+	if count < 1 || count > MaxNodes {
+		problem("nodes.count", "must be between 1 and %d, got %d", MaxNodes, count)
+	}
+
+	if len(config.Nodes.Overrides) > count {
+		problem("nodes.overrides", "has settings for %d nodes but only %d are started", len(config.Nodes.Overrides), count)
+	}
+	for i, node := range config.Nodes.Overrides {
+		for _, err := range checkNode(node) {
+			problem("nodes.overrides", "node %d: %s", i, err.Error())
+		}
+	}
+
+	if config.Cluster != "" {
+		if err := orchestrator.ValidateClusterName(config.Cluster); err != nil {
+			problem("cluster", "%s", err.Error())
+		}
+	}
+
+	// the switch picks the source of the images and reports what conflicts with it, while the
+	// value of every source given is checked below, so that all problems are reported together
+	switch {
+	case config.SwarmBinary != "" || config.GethBinary != "":
+		for _, other := range [][2]string{{"repo", config.Repo}, {"local-src", config.LocalSrc}, {"image", config.Image}} {
+			if other[1] != "" {
+				problem("swarm-binary", "can't be used together with %s %q, choose one", other[0], other[1])
+			}
+		}
+		if config.SwarmBinary == "" {
+			problem("swarm-binary", "is required with geth-binary")
+		}
+		if config.GethBinary == "" {
+			problem("geth-binary", "is required with swarm-binary, geth creates the account of every node")
+		}
+		if checkout(config) {
+			problem("checkout", "only applies to images built from repo, not to swarm-binary %q", config.SwarmBinary)
+		}
+	case config.Image != "":
+		if config.Repo != "" {
+			problem("image", "can't be used together with repo %q, choose one", config.Repo)
+		}
+		if config.LocalSrc != "" {
+			problem("image", "can't be used together with local-src %q, choose one", config.LocalSrc)
+		}
+		if checkout(config) {
+			problem("checkout", "only applies to images built from repo, not to image %q", config.Image)
+		}
+	case config.Repo != "" && config.LocalSrc != "":
+		problem("local-src", "can't be used together with repo %q, choose one", config.Repo)
+	case config.Repo == "" && config.LocalSrc == "":
+		problem("repo", "one of repo, local-src, image or swarm-binary is required")
+	case config.Repo == "":
+		if checkout(config) {
+			problem("checkout", "only applies to images built from repo, not to local-src %q", config.LocalSrc)
+		}
+	}
+
+	if config.Repo != "" {
+		if err := checkRepoURL(config.Repo); err != nil {
+			problem("repo", "%s", err.Error())
+		}
+	}
+	if config.LocalSrc != "" {
+		if err := checkDir(config.LocalSrc); err != nil {
+			problem("local-src", "%s", err.Error())
+		}
+	}
+	if config.Image != "" {
+		if _, err := reference.ParseNormalizedNamed(config.Image); err != nil {
+			problem("image", "%q is not a Docker image reference: %s", config.Image, err.Error())
+		}
+	}
+	if config.SwarmBinary != "" {
+		if err := checkExecutable(config.SwarmBinary); err != nil {
+			problem("swarm-binary", "%s", err.Error())
+		}
+	}
+	if config.GethBinary != "" {
+		if err := checkExecutable(config.GethBinary); err != nil {
+			problem("geth-binary", "%s", err.Error())
+		}
+	}
+
+	if config.ENS != "" {
+		if err := checkENS(config.ENS); err != nil {
+			problem("ens-api", "%s", err.Error())
+		}
+	}
+
+	if config.Path != "" {
+		if err := checkAssets(config); err != nil {
+			problem("path", "%s", err.Error())
+		}
+	}
+
+	for _, err := range checkMounts(config.Add) {
+		problem("add", "%s", err.Error())
+	}
+
+	if err := output.Validate(config.Output); err != nil {
+		problem("output", "%s", err.Error())
+	}
+
+	if config.ReadyTimeout < 0 {
+		problem("ready_timeout", "must not be negative, got %s", config.ReadyTimeout)
+	}
+	if config.ReadyBackoff < 0 {
+		problem("ready_backoff", "must not be negative, got %s", config.ReadyBackoff)
+	}
+
+	if count >= 1 && count <= MaxNodes {
+		if _, err := topology.Edges(config.Topology, count); err != nil {
+			problem("topology.type", "%s", err.Error())
+		}
+
+		for _, err := range netem.Validate(config.Netem, count) {
+			problem("netem", "%s", err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return &util.ConfigError{Problems: problems}
+	}
+
+	return nil
+}
+
+// checkout returns whether the config, or any node, checks out a branch, tag or commit.
+func checkout(config models.Config) bool {
+	if config.Checkout != "" {
+		return true
+	}
+	for _, node := range config.Nodes.Overrides {
+		if node.Checkout != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkNode checks the settings a single node overrides.
+func checkNode(node models.NodeConfig) []error {
+	var errs []error
+
+	if node.ENS != "" {
+		if err := checkENS(node.ENS); err != nil {
+			errs = append(errs, errors.Errorf("ens-api %s", err.Error()))
+		}
+	}
+
+	if node.Verbosity != nil && (*node.Verbosity < 0 || *node.Verbosity > 5) {
+		errs = append(errs, errors.Errorf("verbosity must be between 0 and 5, got %d", *node.Verbosity))
+	}
+
+	if node.CPUs < 0 {
+		errs = append(errs, errors.Errorf("cpus must not be negative, got %g", node.CPUs))
+	}
+
+	if node.Memory != "" {
+		if _, err := units.RAMInBytes(node.Memory); err != nil {
+			errs = append(errs, errors.Errorf("memory %q is not a size like 512m or 2g", node.Memory))
+		}
+	}
+
+	return errs
+}
+
+// checkRepoURL accepts http(s), git, ssh and file URLs as well as scp style Git URLs.
+func checkRepoURL(repo string) error {
+	if scpLike.MatchString(repo) {
+		return nil
+	}
+
+	u, err := url.Parse(repo)
+	if err != nil {
+		return errors.Errorf("%q is not a valid URL: %s", repo, err.Error())
+	}
+
+	switch u.Scheme {
+	case "http", "https", "git", "ssh":
+		if u.Host == "" {
+			return errors.Errorf("%q has no host", repo)
+		}
+	case "file":
+		if u.Path == "" {
+			return errors.Errorf("%q has no path", repo)
+		}
+	default:
+		return errors.Errorf("%q is not a Git URL, expected http(s)://, git://, ssh://, file:// or user@host:path", repo)
+	}
+
+	return nil
+}
+
+// checkENS checks the URL part of an ens-api value. Values without a URL, such as IPC paths,
+// are passed to Swarm as they are.
+func checkENS(ens string) error {
+	raw := embeddedURL.FindString(ens)
+	if raw == "" {
+		return nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.Errorf("%q is not a valid URL: %s", raw, err.Error())
+	}
+	if u.Host == "" {
+		return errors.Errorf("%q has no host", raw)
+	}
+
+	return nil
+}
+
+func checkDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Errorf("%s does not exist", path)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("%s is not a directory", path)
+	}
+
+	return nil
+}
+
+// checkAssets checks that the custom asset directory has the Dockerfile and start script the
+// image of the config is built from.
+func checkAssets(config models.Config) error {
+	if err := checkDir(config.Path); err != nil {
+		return err
+	}
+
+	dockerfile := "Dockerfile"
+	switch {
+	case config.SwarmBinary != "":
+		dockerfile = "Dockerfile.Binary"
+	case config.LocalSrc != "":
+		dockerfile = "Dockerfile.SrcDir"
+	case config.Image != "":
+		return nil
+	}
+
+	for _, name := range []string{dockerfile, "start.sh"} {
+		if _, err := os.Stat(filepath.Join(config.Path, name)); err != nil {
+			return errors.Errorf("%s has no %s", config.Path, name)
+		}
+	}
+
+	return nil
+}
+
+// checkMounts checks that the added directories exist and that each of them is mounted at a
+// different absolute path.
+func checkMounts(mounts models.Mounts) []error {
+	var errs []error
+	targets := map[string]bool{}
+
+	for _, mount := range mounts {
+		if _, err := os.Stat(mount.Source); err != nil {
+			errs = append(errs, errors.Errorf("%s does not exist", mount.Source))
+		}
+
+		target := mount.Target
+		if target == "" {
+			target = models.DefaultMountTarget
+		}
+		if !path.IsAbs(target) {
+			errs = append(errs, errors.Errorf("target of %s must be an absolute path, got %s", mount.Source, target))
+		}
+		if targets[path.Clean(target)] {
+			errs = append(errs, errors.Errorf("more than one directory is mounted at %s", target))
+		}
+		targets[path.Clean(target)] = true
+
+		if mount.Mode != "" && mount.Mode != "ro" && mount.Mode != "rw" {
+			errs = append(errs, errors.Errorf("mode of %s must be ro or rw, got %s", mount.Source, mount.Mode))
+		}
+	}
+
+	return errs
+}
+
+func checkExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Errorf("%s does not exist", path)
+	}
+	if !info.Mode().IsRegular() {
+		return errors.Errorf("%s is not a file", path)
+	}
+	if info.Mode()&0111 == 0 {
+		return errors.Errorf("%s is not executable", path)
+	}
+
+	return nil
+}
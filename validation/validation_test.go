@@ -0,0 +1,173 @@
+package validation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MainframeHQ/swarmer/models"
+	"github.com/MainframeHQ/swarmer/util"
+)
+
+func validConfig() models.Config {
+	return models.Config{
+		Repo:     "https://github.com/ethereum/go-ethereum",
+		Nodes:    models.Nodes{Count: 3},
+		Cluster:  "swarmer",
+		ENS:      "https://mainnet.infura.io/v3/<REPLACE-WITH-YOUR-INFURA-KEY>",
+		Output:   "json",
+		Topology: models.Topology{Type: "ring"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(validConfig(), nil); err != nil {
+		t.Errorf("Config should be valid: %s", err.Error())
+	}
+
+	for _, repo := range []string{"git@github.com:ethereum/go-ethereum.git", "ssh://git@github.com/ethereum/go-ethereum", "file:///src/go-ethereum"} {
+		config := validConfig()
+		config.Repo = repo
+		if err := Validate(config, nil); err != nil {
+			t.Errorf("Repo %s should be valid: %s", repo, err.Error())
+		}
+	}
+
+	verbosity := 0
+	config := validConfig()
+	config.Nodes.Overrides = []models.NodeConfig{{}, {Checkout: "v1.8.17", Verbosity: &verbosity, CPUs: 0.5, Memory: "512m"}}
+	if err := Validate(config, nil); err != nil {
+		t.Errorf("Node overrides should be valid: %s", err.Error())
+	}
+
+	config = validConfig()
+	config.Repo = ""
+	config.Image = "ethdevops/swarm:latest"
+	if err := Validate(config, nil); err != nil {
+		t.Errorf("A published image should be valid without a repo: %s", err.Error())
+	}
+
+	config = validConfig()
+	config.Repo = ""
+	config.SwarmBinary = os.Args[0]
+	config.GethBinary = os.Args[0]
+	if err := Validate(config, nil); err != nil {
+		t.Errorf("Binaries built on the host should be valid without a repo: %s", err.Error())
+	}
+
+	config = validConfig()
+	config.Path = "../docker"
+	if err := Validate(config, nil); err != nil {
+		t.Errorf("The docker directory should be a valid asset directory: %s", err.Error())
+	}
+
+	config = validConfig()
+	config.ENS = "test:0x0123456789abcdef@http://localhost:8545"
+	if err := Validate(config, nil); err != nil {
+		t.Errorf("ENS API with a TLD and contract address should be valid: %s", err.Error())
+	}
+}
+
+func TestValidate_Problems(t *testing.T) {
+	tests := []struct {
+		key    string
+		modify func(*models.Config)
+	}{
+		{"nodes.count", func(c *models.Config) { c.Nodes.Count = 0 }},
+		{"nodes.count", func(c *models.Config) { c.Nodes.Count = MaxNodes + 1 }},
+		{"cluster", func(c *models.Config) { c.Cluster = "has space" }},
+		{"repo", func(c *models.Config) { c.Repo = "" }},
+		{"repo", func(c *models.Config) { c.Repo = "github.com/ethereum/go-ethereum" }},
+		{"repo", func(c *models.Config) { c.Repo = "https:///go-ethereum" }},
+		{"local-src", func(c *models.Config) { c.LocalSrc = "." }},
+		{"local-src", func(c *models.Config) { c.Repo = ""; c.LocalSrc = "non existent directory" }},
+		{"image", func(c *models.Config) { c.Image = "ethdevops/swarm" }},
+		{"image", func(c *models.Config) { c.Repo = ""; c.Image = "Not A/Reference" }},
+		{"checkout", func(c *models.Config) { c.Repo = ""; c.Image = "ethdevops/swarm"; c.Checkout = "master" }},
+		{"checkout", func(c *models.Config) { c.Repo = ""; c.LocalSrc = "."; c.Checkout = "master" }},
+		{"swarm-binary", func(c *models.Config) { c.SwarmBinary = os.Args[0]; c.GethBinary = os.Args[0] }},
+		{"geth-binary", func(c *models.Config) { c.Repo = ""; c.SwarmBinary = os.Args[0] }},
+		{"swarm-binary", func(c *models.Config) { c.Repo = ""; c.SwarmBinary = "validation.go"; c.GethBinary = os.Args[0] }},
+		{"ens-api", func(c *models.Config) { c.ENS = "http://" }},
+		{"add", func(c *models.Config) { c.Add = models.Mounts{{Source: "non existent directory"}} }},
+		{"add", func(c *models.Config) { c.Add = models.Mounts{{Source: ".", Target: "data"}} }},
+		{"add", func(c *models.Config) { c.Add = models.Mounts{{Source: ".", Mode: "wo"}} }},
+		{"add", func(c *models.Config) { c.Add = models.Mounts{{Source: "."}, {Source: "..", Target: "/swarmer/"}} }},
+		{"path", func(c *models.Config) { c.Path = "non existent directory" }},
+		{"path", func(c *models.Config) { c.Path = "." }},
+		{"output", func(c *models.Config) { c.Output = "xml" }},
+		{"ready_timeout", func(c *models.Config) { c.ReadyTimeout = -1 }},
+		{"topology.type", func(c *models.Config) { c.Topology.Type = "torus" }},
+		{"nodes.overrides", func(c *models.Config) { c.Nodes.Overrides = make([]models.NodeConfig, 4) }},
+		{"nodes.overrides", func(c *models.Config) { c.Nodes.Overrides = []models.NodeConfig{{Memory: "lots"}} }},
+		{"nodes.overrides", func(c *models.Config) { c.Nodes.Overrides = []models.NodeConfig{{CPUs: -1}} }},
+		{"nodes.overrides", func(c *models.Config) { c.Nodes.Overrides = []models.NodeConfig{{ENS: "http://"}} }},
+		{"netem", func(c *models.Config) { c.Netem = []models.Netem{{Nodes: []int{3}, Loss: 1}} }},
+		{"nodes.overrides", func(c *models.Config) {
+			verbosity := 6
+			c.Nodes.Overrides = []models.NodeConfig{{Verbosity: &verbosity}}
+		}},
+	}
+
+	for _, test := range tests {
+		config := validConfig()
+		test.modify(&config)
+
+		err := Validate(config, nil)
+		configErr, ok := err.(*util.ConfigError)
+		if !ok || len(configErr.Problems) != 1 || configErr.Problems[0].Key != test.key {
+			t.Errorf("Expected a single problem with %s for %+v, got %v", test.key, config, err)
+		}
+	}
+}
+
+func TestValidate_ConflictingSources(t *testing.T) {
+	config := validConfig()
+	config.Repo = "github.com/ethereum/go-ethereum"
+	config.LocalSrc = "non existent directory"
+
+	err := Validate(config, nil)
+	configErr, ok := err.(*util.ConfigError)
+	if !ok {
+		t.Fatalf("Expected a *util.ConfigError, got %v", err)
+	}
+
+	var keys []string
+	for _, problem := range configErr.Problems {
+		keys = append(keys, problem.Key)
+	}
+	if strings.Join(keys, ",") != "local-src,repo,local-src" {
+		t.Errorf("Expected the conflict along with the problems of repo and local-src, got %v", configErr.Problems)
+	}
+}
+
+func TestValidate_Sources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swarmer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "swarmer.yml")
+	err = ioutil.WriteFile(path, []byte("repo: \"https://github.com/ethereum/go-ethereum\"\nnodes: 0\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := validConfig()
+	config.Nodes.Count = 0
+	config.Add = models.Mounts{{Source: "non existent directory"}}
+
+	err = Validate(config, map[string]string{"nodes.count": path, "add": "flag --add"})
+	if err == nil {
+		t.Fatal("Config should have been invalid...")
+	}
+
+	for _, expected := range []string{path + ":2: nodes.count: must be between 1 and", "flag --add: add: non existent directory does not exist"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Error should contain %q, got:\n%s", expected, err.Error())
+		}
+	}
+}